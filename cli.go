@@ -1,16 +1,22 @@
 package mcli
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"reflect"
+	"strconv"
 	"strings"
 )
 
-const showHiddenFlag = "mcli-show-hidden"
+const (
+	showHiddenFlag   = "mcli-show-hidden"
+	experimentalFlag = "mcli-experimental"
+)
 
 // Options specifies optional options for an App.
 type Options struct {
@@ -21,8 +27,24 @@ type Options struct {
 
 	// AllowPosixSTMO enables using the posix-style single token to specify
 	// multiple boolean options. e.g. `-abc` is equivalent to `-a -b -c`.
+	// Counter flags can also be specified in a single token,
+	// e.g. `-vvv` is equivalent to `-v -v -v`.
 	AllowPosixSTMO bool
 
+	// AllowInterspersedFlags enables GNU-style argument permutation,
+	// flags may appear anywhere among positional arguments,
+	// e.g. `cp -r src -f dst` is equivalent to `cp -r -f src dst`.
+	// A "--" terminates flag parsing, all arguments after it are treated
+	// as positional arguments. Arguments which are not defined flags,
+	// e.g. a negative number `-5`, are kept as positional arguments.
+	AllowInterspersedFlags bool
+
+	// AllowCommandAbbreviation enables using any unambiguous prefix of
+	// each command word to invoke a command, e.g. `issue create` can be
+	// invoked by `iss cre`, when no other command word starts with
+	// "iss" or "cre". An ambiguous prefix is reported with the candidates.
+	AllowCommandAbbreviation bool
+
 	// EnableFlagCompletionForAllCommands enables flag completion for
 	// all commands of an application.
 	// By default, flag completion is disabled to avoid unexpectedly running
@@ -35,6 +57,79 @@ type Options struct {
 	// with signature `func()` or `func(*mcli.Context)`.
 	EnableFlagCompletionForAllCommands bool
 
+	// EnvPrefix enables reading environment variables for all flags and
+	// arguments, the env name is derived from the prefix and the long name,
+	// e.g. "MYAPP_DB_HOST" for flag "--db-host" with prefix "MYAPP".
+	// Env names specified by tag `env` take precedence,
+	// tag `env:"-"` opts out a flag or argument.
+	EnvPrefix string
+
+	// EnvListSeparator specifies the separator to split an env value
+	// for slice and map, e.g. "a,b,c" for []string and "k1=v1,k2=v2"
+	// for map[string]string. By default, it is ",".
+	EnvListSeparator string
+
+	// ConfigFile specifies a config file to load flag values from.
+	// The keys in the config file are long names of flags, nested objects
+	// are flattened by joining the keys with "-", e.g. {"db": {"host": "x"}}
+	// sets the flag "--db-host".
+	// Values are taken with precedence: default < config file < env < command line.
+	// It is ignored if the file does not exist.
+	//
+	// JSON files are supported by default, see ConfigDecoders to support
+	// other formats.
+	ConfigFile string
+
+	// EnableConfigFlag adds a global flag "--config" to all commands,
+	// which specifies a config file to load flag values from.
+	// The flag overrides ConfigFile, and the file must exist.
+	// Like other global flags, it is not added to a command which
+	// is parsed with DisableGlobalFlags, a command must not define
+	// a flag named "config" itself.
+	EnableConfigFlag bool
+
+	// ConfigDecoders optionally specifies decoders for config files
+	// by file extension, e.g. ".yaml", ".toml".
+	ConfigDecoders map[string]ConfigDecoder
+
+	// ReportAllErrors makes Parse to check all flags and arguments,
+	// and report all validation errors together, instead of reporting
+	// only the first one. e.g. required flags which are not set,
+	// values which are not valid enums, or values which cannot be
+	// converted to the type of flags and arguments.
+	// When more than one error is found, the reported error is
+	// a ValidationErrors.
+	ReportAllErrors bool
+
+	// EnableExperimental enables commands marked by CmdOpt Experimental.
+	// Experimental commands can also be enabled by the environment
+	// variable specified by ExperimentalEnv, or the special flag
+	// "--mcli-experimental".
+	EnableExperimental bool
+
+	// ExperimentalEnv optionally specifies an environment variable to
+	// enable experimental commands, e.g. "MYAPP_EXPERIMENTAL",
+	// the commands are enabled when the value is true, e.g. "1", "true".
+	ExperimentalEnv string
+
+	// EnablePlugins enables git-style external plugin commands.
+	// When no command matches the first word of the command line,
+	// an executable named "<program>-<word>" is searched in PluginDirs,
+	// or absolute directories in $PATH if PluginDirs is empty, and is
+	// executed with the remaining arguments and the environment variables.
+	// Flags before the word are also passed to the plugin, e.g.
+	// "<program> -v hello x" runs "<program>-hello -v x".
+	// Plugins are not available if a root command is added by AddRoot,
+	// the root command receives the arguments instead.
+	// The plugins found are listed in help under "Plugins", and their
+	// names are completed by shell completion.
+	// Commands take precedence over plugins with same names.
+	EnablePlugins bool
+
+	// PluginDirs optionally specifies directories to search for plugins,
+	// instead of searching the directories in $PATH.
+	PluginDirs []string
+
 	// HelpFooter optionally adds a footer message to help output.
 	// If Parse is called with option `WithFooter`, the option function's
 	// output overrides this setting.
@@ -68,6 +163,14 @@ type App struct {
 
 	ctx *parsingContext
 
+	// isRunE tells that the App is running by RunE,
+	// errors are returned to the caller instead of exiting the program.
+	isRunE bool
+
+	// exitSig records the exitSignal raised when running by RunE,
+	// in case that it is swallowed by a recover in user command.
+	exitSig *exitSignal
+
 	completionCmdName string
 	isCompletion      bool
 	completionCtx     completionCtx
@@ -134,6 +237,10 @@ type parsingContext struct {
 	fs      *flag.FlagSet
 	flagErr error
 
+	// flagErrReturned tells that flagErr, which is already reported,
+	// is returned by a command created by NewCommandE.
+	flagErrReturned bool
+
 	name string
 	args *[]string
 	opts *parseOptions
@@ -142,12 +249,36 @@ type parsingContext struct {
 	isHelpCmd     bool
 	showHidden    bool
 
+	// enableExperimental tells that experimental commands are enabled
+	// by the special flag "--mcli-experimental".
+	enableExperimental bool
+
+	// abbrevCandidates are the candidate commands of an ambiguous
+	// command abbreviation.
+	abbrevCandidates []string
+
+	// errs collects errors to report together,
+	// when Options.ReportAllErrors is enabled.
+	errs []error
+
 	cmd      *Command
 	flagMap  map[string]*_flag
 	flags    []*_flag
 	nonflags []*_flag
 	envVars  []*_flag
 	parsed   bool
+
+	passthrough *_flag
+	flagGroups  []*flagGroup
+	validators  []any
+
+	// headings are the help headings of prefixed nested structs,
+	// in the order of declaration.
+	headings []string
+
+	// warnings are printed after parsing succeeds, e.g. when
+	// deprecated or renamed flags are used.
+	warnings []string
 }
 
 func (ctx *parsingContext) getFlagSet() *flag.FlagSet {
@@ -170,7 +301,7 @@ func (ctx *parsingContext) getInvalidCmdName() string {
 func (ctx *parsingContext) parseTags(rv reflect.Value) (err error) {
 	fs := ctx.getFlagSet()
 	flagMap := make(map[string]*_flag)
-	flags, nonflags, envVars, err := parseFlags(false, fs, rv, flagMap, ctx.opts)
+	parsed, err := parseFlags(false, fs, rv, flagMap, ctx.opts)
 	if err != nil {
 		if _, ok := err.(*programingError); ok {
 			panic(fmt.Sprintf("mcli: %v", err))
@@ -178,10 +309,28 @@ func (ctx *parsingContext) parseTags(rv reflect.Value) (err error) {
 		ctx.failError(err)
 		return err
 	}
+	for _, f := range parsed.flags {
+		if f.name == configFlagName && ctx.hasConfigFlag() {
+			continue
+		}
+		if ctx.app.isConfigEnabled() {
+			f.configKey = f.name
+		}
+		f.setEnvPrefix(ctx.app.EnvPrefix)
+	}
+	for _, f := range parsed.nonflags {
+		f.setEnvPrefix(ctx.app.EnvPrefix)
+	}
 	ctx.flagMap = flagMap
-	ctx.flags = flags
-	ctx.nonflags = nonflags
-	ctx.envVars = envVars
+	if err = ctx.resolveFlagGroups(); err != nil {
+		panic(fmt.Sprintf("mcli: %v", err))
+	}
+	ctx.flags = parsed.flags
+	ctx.nonflags = parsed.nonflags
+	ctx.envVars = parsed.envVars
+	ctx.validators = parsed.validators
+	ctx.headings = parsed.headings
+	ctx.passthrough = parsed.passthrough
 	ctx.parsed = true
 	return nil
 }
@@ -211,8 +360,17 @@ func (ctx *parsingContext) parseNonflags() (allArgs []string, err error) {
 		arg := allArgs[j]
 		e := f.Set(arg)
 		if e != nil {
-			ctx.failf(&err, "invalid value %q for %s: %v", arg, f.helpName(), e)
-			return
+			ctx.fail(&err, &InvalidValueError{
+				Name:       f.name,
+				IsArgument: true,
+				Value:      arg,
+				Err:        e,
+			})
+			if err != nil {
+				return
+			}
+		} else {
+			f.origin = ValueOrigin{Source: SourceCommandLine}
 		}
 		if !(f.isSlice() || f.isMap()) {
 			i++
@@ -220,56 +378,87 @@ func (ctx *parsingContext) parseNonflags() (allArgs []string, err error) {
 		j++
 	}
 	if j < len(allArgs) {
-		err = &unexpectedArgsError{allArgs[j:]}
-		ctx.failError(err)
-		return
+		ctx.fail(&err, &UnexpectedArgsError{Args: allArgs[j:]})
+		if err != nil {
+			return
+		}
 	}
 	return allArgs, nil
 }
 
-func (ctx *parsingContext) readEnvValues() (err error) {
-	fs := ctx.getFlagSet()
-	for _, f := range ctx.flags {
-		if !f.isSlice() && !f.isMap() {
-			_, err = readEnv(fs, f)
-			if err != nil {
-				return err
-			}
-		}
+// takePassthroughArgs sets the arguments after terminator "--" to
+// the pass-through argument, which is marked by modifier `P`,
+// it returns the arguments before "--".
+func (ctx *parsingContext) takePassthroughArgs(args []string) []string {
+	f := ctx.passthrough
+	if f == nil {
+		return args
 	}
-	for _, f := range ctx.nonflags {
-		if !f.isSlice() && !f.isMap() {
-			_, err = readEnv(fs, f)
-			if err != nil {
-				return err
-			}
-		}
+	args, rest, found := splitPassthroughArgs(ctx.flagMap, args)
+	if found {
+		f.rv.Set(reflect.ValueOf(rest).Convert(f.rv.Type()))
+		f.origin = ValueOrigin{Source: SourceCommandLine}
 	}
-	for _, f := range ctx.envVars {
-		if !f.isSlice() && !f.isMap() {
-			_, err = readEnv(fs, f)
-			if err != nil {
-				return err
+	return args
+}
+
+func (ctx *parsingContext) readEnvValues() (err error) {
+	fs := ctx.getFlagSet()
+	sep := ctx.app.getEnvListSeparator()
+	for _, flags := range [][]*_flag{ctx.flags, ctx.nonflags, ctx.envVars} {
+		for _, f := range flags {
+			if _, e := readEnv(fs, f, sep); e != nil {
+				ctx.fail(&err, e)
+				if err != nil {
+					return err
+				}
 			}
 		}
 	}
 	return nil
 }
 
-func readEnv(fs *flag.FlagSet, f *_flag) (found bool, err error) {
+func (p *App) getEnvListSeparator() string {
+	if p.EnvListSeparator != "" {
+		return p.EnvListSeparator
+	}
+	return ","
+}
+
+// readEnv reads value of f from environment variables,
+// the value of a slice or map is split by sep, unless the flag
+// specifies its own separator by tag `sep`.
+func readEnv(fs *flag.FlagSet, f *_flag, sep string) (found bool, err error) {
 	for _, name := range f.envNames {
 		value := os.Getenv(name)
 		if value == "" {
 			continue
 		}
 		found = true
-		if f.nonflag || f.isEnvVar {
+		switch {
+		case f.isCompositeType():
+			err = setFlagElems(fs, f, f.splitListValue(value, sep))
+		case f.nonflag || f.isEnvVar:
 			err = f.Set(value)
-		} else {
-			err = fs.Set(f.name, value)
+		default:
+			err = setFlag(fs, f.name, value)
+		}
+		// Values of slice, map and counter from env are replaced, instead of
+		// accumulated, when the flag or argument is given from command line.
+		if err == nil && f.isAccumulative() {
+			f.resetOnSet = true
+		}
+		if err == nil {
+			f.origin = ValueOrigin{Source: SourceEnv, EnvName: name}
 		}
 		if err != nil {
-			err = fmt.Errorf("invalid value %q for %s from env %s: %v", value, f.helpName(), name, err)
+			err = &InvalidValueError{
+				Name:       f.name,
+				IsArgument: f.nonflag,
+				Env:        name,
+				Value:      value,
+				Err:        err,
+			}
 		}
 		break
 	}
@@ -279,19 +468,29 @@ func readEnv(fs *flag.FlagSet, f *_flag) (found bool, err error) {
 func (ctx *parsingContext) checkRequired() (err error) {
 	for _, f := range ctx.flags {
 		if f.required && f.isZero() {
-			ctx.failf(&err, "flag is required but not set: -%s", f.name)
-			return
+			ctx.fail(&err, &MissingFlagError{Flag: f.name})
+			if err != nil {
+				return
+			}
 		}
 	}
 	for _, f := range ctx.nonflags {
 		if f.required && f.isZero() {
-			ctx.failf(&err, "argument is required but not given: %v", f.name)
+			ctx.fail(&err, &MissingArgumentError{Argument: f.name})
+			if err != nil {
+				return
+			}
+		}
+	}
+	if f := ctx.passthrough; f != nil && f.required && f.isZero() {
+		ctx.fail(&err, &MissingArgumentError{Argument: f.name})
+		if err != nil {
 			return
 		}
 	}
 	for _, f := range ctx.envVars {
 		if f.required && f.isZero() {
-			ctx.failf(&err, "environment variable is required but not set: %v", strings.Join(f.envNames, ", "))
+			ctx.fail(&err, &MissingEnvError{EnvNames: f.envNames})
 		}
 	}
 	return
@@ -301,10 +500,35 @@ func (ctx *parsingContext) checkEnums() (err error) {
 	flags := append(clip(ctx.flags), ctx.nonflags...)
 	for _, f := range flags {
 		if !f.isZero() && len(f.enums) > 0 {
-			val := f.String()
-			valid := find(f.enums, val) >= 0
+			val, valid := f.checkEnums()
 			if !valid {
-				ctx.failf(&err, "value for %s is invalid, must be one of: %s", f.helpName(), strings.Join(f.enums, ", "))
+				ctx.fail(&err, &InvalidEnumError{
+					Name:       f.name,
+					IsArgument: f.nonflag,
+					Value:      val,
+					Enums:      f.enums,
+				})
+				if err != nil {
+					return
+				}
+			}
+		}
+	}
+	return
+}
+
+func (ctx *parsingContext) checkConstraints() (err error) {
+	// A flag which is set explicitly is checked, even if it is zero.
+	isSet := make(map[string]bool)
+	ctx.getFlagSet().Visit(func(ff *flag.Flag) {
+		isSet[ff.Name] = true
+	})
+	flags := append(clip(ctx.flags), ctx.nonflags...)
+	flags = append(flags, ctx.envVars...)
+	for _, f := range flags {
+		if e := f.checkConstraints(!f.nonflag && isSet[f.name]); e != nil {
+			ctx.fail(&err, e)
+			if err != nil {
 				return
 			}
 		}
@@ -312,49 +536,192 @@ func (ctx *parsingContext) checkEnums() (err error) {
 	return
 }
 
-func (ctx *parsingContext) failf(errp *error, format string, a ...any) {
-	err := fmt.Errorf(format, a...)
+// printWarnings prints warnings about the usage of deprecated and renamed
+// flags and arguments.
+func (ctx *parsingContext) printWarnings() {
+	flags := append(clip(ctx.flags), ctx.nonflags...)
+	flags = append(flags, ctx.envVars...)
+	for _, f := range flags {
+		if !f.deprecated || !f.origin.isExplicit() {
+			continue
+		}
+		msg := f.helpName() + " is deprecated"
+		if f.deprecatedMsg != "" {
+			msg += ", " + f.deprecatedMsg
+		}
+		ctx.warnings = append(ctx.warnings, msg)
+	}
+	out := ctx.getFlagSet().Output()
+	for _, msg := range ctx.warnings {
+		fmt.Fprintf(out, "Warning: %s\n", msg)
+	}
+	ctx.warnings = nil
+}
+
+// fail reports err and stores it to errp.
+// If Options.ReportAllErrors is enabled, err is collected to be reported
+// later by reportErrors, and errp is not changed.
+func (ctx *parsingContext) fail(errp *error, err error) {
+	if ctx.app.ReportAllErrors {
+		ctx.errs = append(ctx.errs, err)
+		return
+	}
 	if *errp == nil {
 		*errp = err
 	}
 	ctx.failError(err)
 }
 
+// reportErrors reports the errors collected when Options.ReportAllErrors
+// is enabled.
+func (ctx *parsingContext) reportErrors() error {
+	var err error
+	switch len(ctx.errs) {
+	case 0:
+		return nil
+	case 1:
+		err = ctx.errs[0]
+	default:
+		err = ValidationErrors(ctx.errs)
+	}
+	ctx.failError(err)
+	return err
+}
+
+// failNow reports err which stops parsing immediately.
+// If Options.ReportAllErrors is enabled, err is reported together with
+// the errors collected so far.
+func (ctx *parsingContext) failNow(err error) error {
+	if !ctx.app.ReportAllErrors {
+		ctx.failError(err)
+		return err
+	}
+	ctx.errs = append(ctx.errs, err)
+	return ctx.reportErrors()
+}
+
 func (ctx *parsingContext) failError(err error) {
 	fs := ctx.getFlagSet()
 	out := fs.Output()
 	fmt.Fprintln(out, err.Error())
-	if _, ok := err.(*invalidCmdError); ok {
-		ctx.app.printSuggestions(ctx.getInvalidCmdName())
+	var invalidCmdErr *InvalidCommandError
+	var experimentalErr *ExperimentalCommandError
+	switch {
+	case errors.As(err, &invalidCmdErr):
+		ctx.app.printSuggestions(invalidCmdErr.Suggestions)
+		fmt.Fprintln(out, "")
+	case errors.As(err, &experimentalErr):
 		fmt.Fprintln(out, "")
-	} else {
+	default:
 		fs.Usage()
 	}
 
 	// Keep same behavior with (*flag.FlagSet).Parse.
 	switch fs.ErrorHandling() {
 	case flag.ExitOnError:
-		os.Exit(2)
+		ctx.app.exit(2, err)
 	case flag.PanicOnError:
 		panic(err)
 	}
 }
 
-func (p *App) printSuggestions(invalidCmdName string) {
-	cmds := p.cmds
+// parseFlagSet parses flags from args and populates the FlagSet,
+// it keeps same behavior with (*flag.FlagSet).Parse, except that
+// when the App is running by RunE, errors are returned to RunE instead
+// of exiting the program.
+// The remaining non-flag arguments are available by fs.Args().
+func (ctx *parsingContext) parseFlagSet(args []string) (err error) {
+	fs := ctx.getFlagSet()
+	defer func() { setFlagSetArgs(fs, args) }()
+	for len(args) > 0 {
+		var v *flagValue
+		var stop bool
+		var e error
+		args, v, stop, e = parseOneFlag(fs, args)
+		if e == flag.ErrHelp {
+			e = &HelpRequestedError{Command: ctx.name}
+			fs.Usage()
+			switch fs.ErrorHandling() {
+			case flag.ExitOnError:
+				ctx.app.exit(0, nil)
+			case flag.PanicOnError:
+				panic(e)
+			}
+			return e
+		}
+		if e != nil {
+			if _, ok := e.(*InvalidValueError); ok {
+				ctx.fail(&err, e)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			return ctx.failNow(e)
+		}
+		if v != nil {
+			v.f.origin = ValueOrigin{Source: SourceCommandLine}
+			if v.renamedFrom != "" {
+				ctx.warnings = append(ctx.warnings, fmt.Sprintf("%s is renamed, use %s instead",
+					formatHelpName(v.renamedFrom, false), v.f.helpName()))
+			}
+		}
+		if stop {
+			break
+		}
+	}
+	return nil
+}
+
+// exitSignal is used to unwind the stack to RunE, instead of exiting
+// the program when an error occurs.
+type exitSignal struct {
+	err error
+}
+
+// exit exits the program with the given code.
+// If the App is running by RunE, it unwinds the stack to RunE,
+// which returns err to the caller.
+func (p *App) exit(code int, err error) {
+	if p.isRunE {
+		p.exitSig = &exitSignal{err: err}
+		panic(p.exitSig)
+	}
+	os.Exit(code)
+}
+
+func (p *App) printSuggestions(sugg []string) {
 	ctx := p.getParsingContext()
 	out := ctx.getFlagSet().Output()
-	if invalidCmdName != "" {
-		sugg := cmds.suggest(invalidCmdName)
-		if len(sugg) > 0 {
-			fmt.Fprintf(out, "Did you mean this?\n")
-			for _, cmdName := range sugg {
-				fmt.Fprintf(out, "    \t%s\n", cmdName)
-			}
+	if len(sugg) > 0 {
+		fmt.Fprintf(out, "Did you mean this?\n")
+		for _, cmdName := range sugg {
+			fmt.Fprintf(out, "    \t%s\n", cmdName)
 		}
 	}
 }
 
+func newInvalidCmdError(ctx *parsingContext) *InvalidCommandError {
+	invalidCmdName := ctx.getInvalidCmdName()
+	if len(ctx.abbrevCandidates) > 0 {
+		return &InvalidCommandError{
+			GroupName:   ctx.name,
+			Command:     invalidCmdName,
+			Suggestions: ctx.abbrevCandidates,
+			Ambiguous:   true,
+		}
+	}
+	var sugg []string
+	if invalidCmdName != "" {
+		sugg = ctx.app.cmds.suggest(invalidCmdName)
+	}
+	return &InvalidCommandError{
+		GroupName:   ctx.name,
+		Command:     invalidCmdName,
+		Suggestions: sugg,
+	}
+}
+
 func (p *App) printUsage() {
 	newUsagePrinter(p).Do()
 }
@@ -362,9 +729,10 @@ func (p *App) printUsage() {
 // Add adds a command.
 //
 // Param cmd must be type of one of the following:
-//   - `func()`, user should call `mcli.Parse` inside the function
-//   - `func(ctx *mcli.Context)`, user should call `ctx.Parse` inside the function
-//   - a Command created by NewCommand
+//   - `func()` or `func() error`, user should call `mcli.Parse` inside the function
+//   - `func(ctx *mcli.Context)` or `func(ctx *mcli.Context) error`,
+//     user should call `ctx.Parse` inside the function
+//   - a Command created by NewCommand or NewCommandE
 func (p *App) Add(name string, cmd any, description string, opts ...CmdOpt) {
 	p._add(name, cmd, description, opts...)
 }
@@ -395,8 +763,12 @@ func (p *App) validateCommand(cmd any, opts ...CmdOpt) *Command {
 		return x
 	case func():
 		return newUntypedCommand(x, opts...)
+	case func() error:
+		return newUntypedCommandE(x, opts...)
 	case func(*Context):
 		return newUntypedCtxCommand(x, opts...)
+	case func(*Context) error:
+		return newUntypedCtxCommandE(x, opts...)
 	}
 	panic(fmt.Sprintf("mcli: unsupported command type: %T", cmd))
 }
@@ -473,14 +845,13 @@ func (p *App) groupCmd() {
 	p.printUsage()
 }
 
-func (p *App) helpCmd() {
+func (p *App) helpCmd() error {
 	ctx := p.getParsingContext()
 	ctx.isHelpCmd = true
 
 	// i.e. "program help"
 	if len(ctx.ambiguousArgs) == 0 {
-		p.runWithArgs([]string{"-h"}, true)
-		return
+		return p.runWithArgs([]string{"-h"}, true)
 	}
 
 	// i.e. "program help group cmd"
@@ -490,12 +861,13 @@ func (p *App) helpCmd() {
 		// failError will exit the program, we modify ctx.name here to
 		// help to check suggestions.
 		ctx.name = ""
-		ctx.failError(newInvalidCmdError(ctx))
-		return
+		err := newInvalidCmdError(ctx)
+		ctx.failError(err)
+		return err
 	}
 
 	// We got a valid command, print the help.
-	p.runWithArgs(append(ctx.ambiguousArgs, "-h"), true)
+	return p.runWithArgs(append(ctx.ambiguousArgs, "-h"), true)
 }
 
 func (p *App) validateHelpCommand(name string) bool {
@@ -510,6 +882,8 @@ func (p *App) validateHelpCommand(name string) bool {
 // Run is the entry point to an application, it parses the command line
 // and searches for a registered command, it runs the command if a command
 // is found, else it will report an error and exit the program.
+// If the command returns an error, the error is printed and the program
+// exits with code 1.
 //
 // Optionally you may specify args to parse, by default it parses the
 // command line arguments os.Args[1:].
@@ -518,32 +892,144 @@ func (p *App) Run(args ...string) {
 	if len(args) == 0 {
 		args = os.Args[1:]
 	}
-	p.runWithArgs(args, true)
+	if err := p.runWithArgs(args, true); err != nil {
+		os.Exit(p.reportRunError(err))
+	}
 }
 
-func (p *App) runWithArgs(cmdArgs []string, exitOnInvalidCmd bool) {
+// reportRunError prints err returned by running a command, unless it is
+// already reported when parsing flags and arguments, and returns the
+// exit code.
+func (p *App) reportRunError(err error) int {
+	// A plugin prints its own errors, exit with the same code.
+	var exitErr *exec.ExitError
+	if pe, ok := err.(*PluginError); ok && errors.As(pe.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	// Keep same exit code with (*flag.FlagSet).Parse.
+	if p.getParsingContext().flagErrReturned {
+		return 2
+	}
+	fmt.Fprintln(p.getFlagSet().Output(), err.Error())
+	return 1
+}
+
+// RunE is similar to Run, but it never exits the program.
+// Instead, it returns the error which occurs when searching for the command,
+// parsing flags and arguments, or returned by the command itself.
+// It returns nil when help is requested and printed.
+//
+// This allows the program to decide its own exit code and run deferred
+// cleanups, or to embed an application in a larger program.
+//
+// Note that when an error occurs in parsing, RunE unwinds the stack of
+// the command by a panic, which is recovered by RunE. If the command
+// calls recover() around parsing, it should re-panic values it does not
+// know, in case it does not, RunE still returns the parsing error,
+// but the command continues to run after its recover.
+// Using Parse with option `WithErrorHandling(flag.ContinueOnError)`
+// returns the error explicitly without panicking.
+func (p *App) RunE(args ...string) (err error) {
+	defer setRunningApp(p)()
+	if len(args) == 0 {
+		args = os.Args[1:]
+	}
+
+	p.isRunE = true
+	p.exitSig = nil
+	defer func() {
+		p.isRunE = false
+		if r := recover(); r != nil {
+			sig, ok := r.(*exitSignal)
+			if !ok {
+				panic(r)
+			}
+			err = sig.err
+		} else if p.exitSig != nil {
+			// The exitSignal is swallowed by the command.
+			err = p.exitSig.err
+		}
+		p.exitSig = nil
+	}()
+	return p.runWithArgs(args, true)
+}
+
+func (p *App) runWithArgs(cmdArgs []string, exitOnInvalidCmd bool) error {
 	if isComp, userArgs, completionShell := hasCompletionFlag(cmdArgs); isComp {
 		p.setupCompletionCtx(userArgs, completionShell)
 		p.doAutoCompletion(userArgs)
-		return
+		return nil
 	}
 
 	invalidCmdName, found := p.searchCmd(cmdArgs)
 	ctx := p.getParsingContext()
 	if found && ctx.cmd != nil {
-		ctx.cmd.f()
-		return
+		if err := p.checkCmdLifecycle(ctx.cmd); err != nil {
+			ctx.failError(err)
+			if exitOnInvalidCmd {
+				p.exit(2, err)
+			}
+			return err
+		}
+		return ctx.cmd.f()
+	}
+	if !found && p.EnablePlugins && ctx.name == "" {
+		// The first word which is not a command may be a plugin,
+		// flags before it are forwarded to the plugin.
+		if idx := findPluginWord(cmdArgs); idx >= 0 {
+			name := cmdArgs[idx]
+			if path, ok := p.lookupPlugin(name); ok {
+				args := append(clip(cmdArgs[:idx]), cmdArgs[idx+1:]...)
+				return p.runPlugin(name, path, args)
+			}
+		}
 	}
 	if invalidCmdName != "" {
 		err := newInvalidCmdError(ctx)
 		ctx.failError(err)
 		if exitOnInvalidCmd {
-			os.Exit(2)
+			p.exit(2, err)
 		}
-	} else {
-		ctx.showHidden = hasBoolFlag(showHiddenFlag, cmdArgs)
-		p.printUsage()
+		return err
 	}
+	ctx.showHidden = hasBoolFlag(showHiddenFlag, cmdArgs)
+	p.printUsage()
+	return nil
+}
+
+// checkCmdLifecycle checks whether an experimental command is enabled,
+// and prints warnings for deprecated and experimental commands.
+func (p *App) checkCmdLifecycle(cmd *Command) error {
+	opts := cmd.getLifecycleOpts()
+	if !opts.deprecated && !opts.experimental {
+		return nil
+	}
+	out := p.getFlagSet().Output()
+	if opts.experimental {
+		if !p.isExperimentalEnabled() {
+			return &ExperimentalCommandError{Command: cmd.Name, Env: p.ExperimentalEnv}
+		}
+		fmt.Fprintf(out, "Warning: command '%s' is experimental, it may change or be removed in future releases\n", cmd.Name)
+	}
+	if opts.deprecated {
+		msg := fmt.Sprintf("command '%s' is deprecated", cmd.Name)
+		if opts.deprecatedMsg != "" {
+			msg += ", " + opts.deprecatedMsg
+		}
+		fmt.Fprintf(out, "Warning: %s\n", msg)
+	}
+	return nil
+}
+
+func (p *App) isExperimentalEnabled() bool {
+	if p.EnableExperimental || p.getParsingContext().enableExperimental {
+		return true
+	}
+	if p.ExperimentalEnv != "" {
+		enabled, _ := strconv.ParseBool(os.Getenv(p.ExperimentalEnv))
+		return enabled
+	}
+	return false
 }
 
 // searchCmd helps to do testing.
@@ -556,12 +1042,16 @@ func (p *App) searchCmd(cmdArgs []string) (invalidCmdName string, found bool) {
 	}
 
 	ctx := p.getParsingContext()
+	ctx.enableExperimental = hasBoolFlag(experimentalFlag, cmdArgs)
+	if p.AllowCommandAbbreviation {
+		cmdArgs, ctx.abbrevCandidates = cmds.expandAbbrev(cmdArgs)
+	}
 
 	// Check root command.
 	if p.rootCmd != nil {
 		if len(cmdArgs) == 0 ||
 			strings.HasPrefix(cmdArgs[0], "-") ||
-			!p.cmds.isValid(cmdArgs[0]) {
+			(!p.cmds.isValid(cmdArgs[0]) && len(ctx.abbrevCandidates) == 0) {
 
 			flagIdx := findFlagIndex(cmdArgs)
 			args := cmdArgs[flagIdx:]
@@ -608,10 +1098,12 @@ func (p *App) parseArgs(v any, opts ...ParseOpt) (fs *flag.FlagSet, err error) {
 
 	wrapArgs := &withGlobalFlagArgs{
 		GlobalFlags: nil,
+		ConfigFlags: nil,
 		CmdArgs:     v,
 	}
 	if !ctx.opts.disableGlobalFlags {
 		wrapArgs.GlobalFlags = p.getGlobalFlags()
+		wrapArgs.ConfigFlags = p.getConfigFlags()
 	}
 
 	fs = ctx.getFlagSet()
@@ -630,6 +1122,9 @@ func (p *App) parseArgs(v any, opts ...ParseOpt) (fs *flag.FlagSet, err error) {
 		ctx.showHidden = true
 		fs.BoolVar(&ctx.showHidden, showHiddenFlag, true, "show hidden commands and flags")
 	}
+	if hasBoolFlag(experimentalFlag, cmdArgs) {
+		fs.BoolVar(&ctx.enableExperimental, experimentalFlag, true, "enable experimental commands")
+	}
 
 	// For completion, we parse the command arguments,
 	// then transmit the executing to `continueCompletion`.
@@ -650,7 +1145,11 @@ func (p *App) parseArgsForRunningCommand() (fs *flag.FlagSet, err error) {
 		cmdArgs = ctx.reorderFlags(cmdArgs)
 	}
 
-	// Read env values before parsing command line flags and arguments.
+	// Read config file and env values before parsing command line
+	// flags and arguments, values from command line take precedence.
+	if err = ctx.readConfigValues(cmdArgs); err != nil {
+		return fs, err
+	}
 	if err = ctx.readEnvValues(); err != nil {
 		return fs, err
 	}
@@ -658,17 +1157,24 @@ func (p *App) parseArgsForRunningCommand() (fs *flag.FlagSet, err error) {
 	// If the command does not receive arguments, but there are still
 	// arguments before flags, it is absolutely an invalid command.
 	if !checkNonflagsLength(ctx.nonflags, ctx.ambiguousArgs) {
-		err = newInvalidCmdError(ctx)
-		ctx.failError(err)
+		err = ctx.failNow(newInvalidCmdError(ctx))
 		return fs, err
 	}
 
+	// Take the arguments after "--" for the pass-through argument.
+	cmdArgs = ctx.takePassthroughArgs(cmdArgs)
+
 	// Expand the posix-style single-token-multiple-values flags.
 	if p.Options.AllowPosixSTMO {
 		cmdArgs = expandSTMOFlags(ctx.flagMap, cmdArgs)
 	}
 
-	if err = fs.Parse(cmdArgs); err != nil {
+	// Move flags interspersed with positional arguments to front.
+	if p.Options.AllowInterspersedFlags {
+		cmdArgs = permuteFlags(ctx.flagMap, cmdArgs)
+	}
+
+	if err = ctx.parseFlagSet(cmdArgs); err != nil {
 		return fs, err
 	}
 	nonflagArgs, err := ctx.parseNonflags()
@@ -681,7 +1187,20 @@ func (p *App) parseArgsForRunningCommand() (fs *flag.FlagSet, err error) {
 	if err = ctx.checkEnums(); err != nil {
 		return fs, err
 	}
-	tidyFlags(fs, ctx.flags, nonflagArgs)
+	if err = ctx.checkConstraints(); err != nil {
+		return fs, err
+	}
+	if err = ctx.checkFlagGroups(); err != nil {
+		return fs, err
+	}
+	if err = ctx.runValidators(); err != nil {
+		return fs, err
+	}
+	if err = ctx.reportErrors(); err != nil {
+		return fs, err
+	}
+	ctx.printWarnings()
+	setFlagSetArgs(fs, nonflagArgs)
 	return fs, err
 }
 
@@ -735,7 +1254,7 @@ func expandSTMOFlags(flagMap map[string]*_flag, args []string) []string {
 		shouldExpand := true
 		for i := 0; i < len(name); i++ {
 			f := flagMap[name[i:i+1]]
-			if f == nil || !f.isBoolean() {
+			if f == nil || !(f.isBoolean() || f.counter) {
 				shouldExpand = false
 				break
 			}
@@ -751,6 +1270,65 @@ func expandSTMOFlags(flagMap map[string]*_flag, args []string) []string {
 	return out
 }
 
+// permuteFlags moves flags and their values to the front of args,
+// the positional arguments are kept in order after a "--".
+// A "--" in args terminates the permutation, the remaining args are
+// all treated as positional arguments.
+// Only the defined flags are moved, other args starting with "-",
+// e.g. a negative number "-5", are kept as positional arguments.
+func permuteFlags(flagMap map[string]*_flag, args []string) []string {
+	flags := make([]string, 0, len(args))
+	var nonflags []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			nonflags = append(nonflags, args[i+1:]...)
+			break
+		}
+		if len(a) < 2 || a[0] != '-' {
+			nonflags = append(nonflags, a)
+			continue
+		}
+		name := strings.TrimLeft(a, "-")
+		hasValue := strings.Contains(name, "=")
+		if hasValue {
+			name = name[:strings.IndexByte(name, '=')]
+		}
+		f, ok := lookupPermutedFlag(flagMap, name)
+		if !ok {
+			nonflags = append(nonflags, a)
+			continue
+		}
+		flags = append(flags, a)
+		if !hasValue && f != nil && !f.isBoolFlag() && i+1 < len(args) {
+			flags = append(flags, args[i+1])
+			i++
+		}
+	}
+	if len(nonflags) > 0 {
+		flags = append(flags, "--")
+		flags = append(flags, nonflags...)
+	}
+	return flags
+}
+
+// lookupPermutedFlag tells whether name is a flag to move by
+// permuteFlags, it returns the flag if name is defined in flagMap.
+// The help flags and the special flags of mcli are also moved.
+func lookupPermutedFlag(flagMap map[string]*_flag, name string) (*_flag, bool) {
+	switch name {
+	case "h", "help", showHiddenFlag, experimentalFlag:
+		return nil, true
+	}
+	if f := flagMap[name]; f != nil {
+		return f, true
+	}
+	if f := flagMap[strings.TrimPrefix(name, "no-")]; f != nil && f.negatable && name == f.negatedName() {
+		return f, true
+	}
+	return nil, false
+}
+
 // SetGlobalFlags sets global flags, global flags are available to all commands.
 // DisableGlobalFlags may be used to disable global flags for a specific
 // command when calling Parse.
@@ -763,57 +1341,10 @@ func (p *App) SetGlobalFlags(v any) {
 
 type withGlobalFlagArgs struct {
 	GlobalFlags any
+	ConfigFlags any
 	CmdArgs     any
 }
 
 func getProgramName() string {
 	return filepath.Base(os.Args[0])
 }
-
-func newProgramingError(format string, args ...any) *programingError {
-	msg := fmt.Sprintf(format, args...)
-	return &programingError{msg: msg}
-}
-
-type programingError struct {
-	msg string
-}
-
-func (e *programingError) Error() string {
-	return e.msg
-}
-
-func newInvalidCmdError(ctx *parsingContext) *invalidCmdError {
-	return &invalidCmdError{
-		groupName:      ctx.name,
-		invalidCmdName: ctx.getInvalidCmdName(),
-	}
-}
-
-type invalidCmdError struct {
-	groupName      string
-	invalidCmdName string
-}
-
-func (e *invalidCmdError) Error() string {
-	cmdName := getProgramName()
-	if e.groupName != "" {
-		cmdName += " " + e.groupName
-	}
-	return fmt.Sprintf("'%s' is not a valid command. See '%s -h' for help.", e.invalidCmdName, cmdName)
-}
-
-type unexpectedArgsError struct {
-	args []string
-}
-
-func (e *unexpectedArgsError) Error() string {
-	return fmt.Sprintf("got unexpected %s", formatErrorArguments(e.args))
-}
-
-func formatErrorArguments(args []string) string {
-	if len(args) == 1 {
-		return fmt.Sprintf("argument: '%s'", args[0])
-	}
-	return fmt.Sprintf("arguments: '%s'", strings.Join(args, " "))
-}
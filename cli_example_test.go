@@ -2,6 +2,7 @@ package mcli
 
 import (
 	"bytes"
+	"errors"
 	"flag"
 	"os"
 	"strings"
@@ -382,7 +383,7 @@ var githubCliBrowseCmd = NewCommand(exampleGithubCliBrowse,
 		#=> Open main.go in the main branch`))
 
 func exampleGithubCliBrowse(ctx *Context, args *githubCliBrowseArgs) {
-	if err := ctx.ArgsError(); err != nil && err != flag.ErrHelp {
+	if err := ctx.ArgsError(); err != nil && !errors.Is(err, flag.ErrHelp) {
 		panic(err)
 	}
 }
@@ -494,7 +495,7 @@ func exampleGithubCliIssueCreate() {
   $ gh issue create --assignee "@me"
   $ gh issue create --project "Roadmap"
 `))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		panic(err)
 	}
 }
@@ -2,6 +2,7 @@ package mcli
 
 import (
 	"bytes"
+	"errors"
 	"flag"
 	"fmt"
 	"os"
@@ -332,7 +333,7 @@ func TestParsing_PointerValues(t *testing.T) {
 			WithArgs([]string{"-h"}),
 			WithErrorHandling(flag.ContinueOnError))
 		_ = fs
-		assert.Equal(t, flag.ErrHelp, err)
+		assert.ErrorIs(t, err, flag.ErrHelp)
 		got := buf.String()
 		want := `
 Flags:
@@ -1007,6 +1008,592 @@ func TestApp_AllowPosixSTMO(t *testing.T) {
 	assert.True(t, args1.EBool)
 }
 
+func TestApp_AllowInterspersedFlags(t *testing.T) {
+	type cmdArgs struct {
+		Recursive bool     `cli:"-r, --recursive"`
+		Force     bool     `cli:"-f, --force"`
+		Mode      string   `cli:"-m, --mode"`
+		Src       string   `cli:"src"`
+		Dst       []string `cli:"dst"`
+	}
+
+	resetDefaultApp()
+	defaultApp.AllowInterspersedFlags = true
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-r", "a", "-m", "0644", "b", "-f", "c"}))
+	assert.Nil(t, err)
+	assert.True(t, args1.Recursive)
+	assert.True(t, args1.Force)
+	assert.Equal(t, "0644", args1.Mode)
+	assert.Equal(t, "a", args1.Src)
+	assert.Equal(t, []string{"b", "c"}, args1.Dst)
+	assert.Equal(t, []string{"a", "b", "c"}, fs.Args())
+
+	resetDefaultApp()
+	defaultApp.AllowInterspersedFlags = true
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"a", "--mode=x", "b", "--", "-f", "c"}))
+	assert.Nil(t, err)
+	assert.False(t, args2.Force)
+	assert.Equal(t, "x", args2.Mode)
+	assert.Equal(t, "a", args2.Src)
+	assert.Equal(t, []string{"b", "-f", "c"}, args2.Dst)
+
+	resetDefaultApp()
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-r", "a", "b", "-f", "c"}))
+	assert.Nil(t, err)
+	assert.False(t, args3.Force)
+	assert.Equal(t, []string{"b", "-f", "c"}, args3.Dst)
+
+	// Args which are not defined flags, e.g. negative numbers,
+	// are kept as positional arguments.
+	type calcArgs struct {
+		Verbose bool `cli:"-v, --verbose"`
+		A       int  `cli:"a"`
+		B       int  `cli:"b"`
+	}
+	resetDefaultApp()
+	defaultApp.AllowInterspersedFlags = true
+	args4 := &calcArgs{}
+	_, err = Parse(args4, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"3", "-5", "-v"}))
+	assert.Nil(t, err)
+	assert.True(t, args4.Verbose)
+	assert.Equal(t, 3, args4.A)
+	assert.Equal(t, -5, args4.B)
+
+	resetDefaultApp()
+	defaultApp.AllowInterspersedFlags = true
+	args5 := &calcArgs{}
+	_, err = Parse(args5, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"3", "-5"}))
+	assert.Nil(t, err)
+	assert.Equal(t, -5, args5.B)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.AllowInterspersedFlags = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&calcArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"3", "-5", "--help"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+}
+
+func TestParse_PassthroughArgs(t *testing.T) {
+	type cmdArgs struct {
+		Verbose   bool     `cli:"-v, --verbose"`
+		Namespace string   `cli:"-n, --namespace"`
+		Pod       string   `cli:"pod, The pod name"`
+		Command   []string `cli:"#P, command, The command to run in the pod"`
+	}
+
+	resetDefaultApp()
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"pod1", "-v", "-n", "--", "--", "ls", "-l", "--", "/tmp"}))
+	assert.Nil(t, err)
+	assert.True(t, args1.Verbose)
+	assert.Equal(t, "--", args1.Namespace)
+	assert.Equal(t, "pod1", args1.Pod)
+	assert.Equal(t, []string{"ls", "-l", "--", "/tmp"}, args1.Command)
+	assert.Equal(t, []string{"pod1"}, fs.Args())
+
+	resetDefaultApp()
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v", "pod2"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "pod2", args2.Pod)
+	assert.Nil(t, args2.Command)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, " [flags] [pod] [-- command...]\n")
+	assert.Contains(t, got, "  -- command...    The command to run in the pod\n")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Command string `cli:"#P, command"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestParse_NegatableBool(t *testing.T) {
+	type cmdArgs struct {
+		Color   bool  `cli:"#N, --color, Colorize the output" default:"true"`
+		Verbose *bool `cli:"#N, -v, --verbose, Print verbose logs"`
+	}
+
+	resetDefaultApp()
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--no-color", "--no-verbose"}))
+	assert.Nil(t, err)
+	assert.False(t, args1.Color)
+	assert.NotNil(t, args1.Verbose)
+	assert.False(t, *args1.Verbose)
+	assert.NotNil(t, fs.Lookup("no-color"))
+
+	resetDefaultApp()
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v"}))
+	assert.Nil(t, err)
+	assert.True(t, args2.Color)
+	assert.NotNil(t, args2.Verbose)
+	assert.True(t, *args2.Verbose)
+
+	resetDefaultApp()
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.True(t, args3.Color)
+	assert.Nil(t, args3.Verbose)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "      --[no-]color      Colorize the output\n")
+	assert.Contains(t, got, "  -v, --[no-]verbose    Print verbose logs\n")
+	assert.NotContains(t, got, "--no-color")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Name string `cli:"#N, --name"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestParse_CounterFlag(t *testing.T) {
+	type cmdArgs struct {
+		Verbose int    `cli:"#C, -v, --verbose, Verbosity level" env:"TEST_MCLI_VERBOSE"`
+		Quiet   uint8  `cli:"#C, -q, Decrease verbosity"`
+		All     bool   `cli:"-a, --all"`
+		Name    string `cli:"name"`
+	}
+
+	resetDefaultApp()
+	defaultApp.AllowPosixSTMO = true
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-vva", "-q", "--verbose", "-qq", "abc"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, args1.Verbose)
+	assert.Equal(t, uint8(3), args1.Quiet)
+	assert.True(t, args1.All)
+	assert.Equal(t, "abc", args1.Name)
+	assert.Equal(t, "3", fs.Lookup("v").Value.String())
+
+	resetDefaultApp()
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v", "--verbose=5", "-v"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 6, args2.Verbose)
+
+	resetDefaultApp()
+	os.Setenv("TEST_MCLI_VERBOSE", "2")
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, args3.Verbose)
+
+	// The value from env or default value is replaced, instead of
+	// increased, when the flag is given from command line.
+	resetDefaultApp()
+	os.Setenv("TEST_MCLI_VERBOSE", "2")
+	args4 := &cmdArgs{}
+	_, err = Parse(args4, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 1, args4.Verbose)
+
+	resetDefaultApp()
+	args5 := &struct {
+		Verbose int `cli:"#C, -v" default:"2"`
+	}{}
+	_, err = Parse(args5, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v", "-v", "-v"}))
+	assert.Nil(t, err)
+	assert.Equal(t, 3, args5.Verbose)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "  -v, --verbose...    Verbosity level\n")
+	assert.Contains(t, got, "  -q...               Decrease verbosity\n")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Timeout time.Duration `cli:"#C, --timeout"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestApp_EnvPrefix(t *testing.T) {
+	type cmdArgs struct {
+		DBHost  string   `cli:"--db-host, The database host"`
+		Port    int      `cli:"-p, --port" env:"TEST_PORT"`
+		Secret  string   `cli:"--secret" env:"-"`
+		Verbose bool     `cli:"-v"`
+		Tags    []string `cli:"--tags"`
+		Name    string   `cli:"name"`
+	}
+
+	resetDefaultApp()
+	defaultApp.EnvPrefix = "MYAPP_"
+	os.Setenv("MYAPP_DB_HOST", "127.0.0.1")
+	os.Setenv("MYAPP_PORT", "1234")
+	os.Setenv("TEST_PORT", "8080")
+	os.Setenv("MYAPP_SECRET", "abc")
+	os.Setenv("MYAPP_V", "true")
+	os.Setenv("MYAPP_NAME", "demo")
+	args := &cmdArgs{}
+	_, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", args.DBHost)
+	assert.Equal(t, 8080, args.Port)
+	assert.Equal(t, "", args.Secret)
+	assert.True(t, args.Verbose)
+	assert.Equal(t, "demo", args.Name)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.EnvPrefix = "MYAPP"
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[env: MYAPP_DB_HOST]")
+	assert.Contains(t, got, "[env: TEST_PORT]")
+	assert.Contains(t, got, "[env: MYAPP_NAME]")
+	assert.NotContains(t, got, "MYAPP_SECRET")
+	assert.Contains(t, got, "[env: MYAPP_TAGS]")
+}
+
+func TestParse_EnvSliceAndMap(t *testing.T) {
+	type cmdArgs struct {
+		Hosts  []string          `cli:"--hosts" env:"TEST_HOSTS"`
+		Ports  []int             `cli:"--ports" env:"TEST_PORTS"`
+		Labels map[string]string `cli:"--labels" env:"TEST_LABELS"`
+		Files  []string          `cli:"files" env:"TEST_FILES"`
+	}
+
+	resetDefaultApp()
+	os.Setenv("TEST_HOSTS", "a, b,c")
+	os.Setenv("TEST_PORTS", "80,443")
+	os.Setenv("TEST_LABELS", "k1=v1,k2=v2")
+	os.Setenv("TEST_FILES", "f1,f2")
+	args1 := &cmdArgs{}
+	_, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, args1.Hosts)
+	assert.Equal(t, []int{80, 443}, args1.Ports)
+	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, args1.Labels)
+	assert.Equal(t, []string{"f1", "f2"}, args1.Files)
+
+	// Values from command line replace values from env.
+	resetDefaultApp()
+	os.Setenv("TEST_HOSTS", "a,b")
+	os.Setenv("TEST_FILES", "f1,f2")
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--hosts", "x", "--hosts", "y", "f3"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "y"}, args2.Hosts)
+	assert.Equal(t, []string{"f3"}, args2.Files)
+
+	resetDefaultApp()
+	defaultApp.EnvListSeparator = ";"
+	os.Setenv("TEST_HOSTS", "a,b;c")
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a,b", "c"}, args3.Hosts)
+
+	resetDefaultApp()
+	os.Setenv("TEST_PORTS", "80,abc")
+	var buf bytes.Buffer
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	var invalidValueErr *InvalidValueError
+	assert.True(t, errors.As(err, &invalidValueErr))
+	assert.Equal(t, "TEST_PORTS", invalidValueErr.Env)
+	assert.Equal(t, "80,abc", invalidValueErr.Value)
+}
+
+func TestParse_SliceAndMapDefaults(t *testing.T) {
+	type cmdArgs struct {
+		Hosts  []string          `cli:"-H, --hosts, The hosts" default:"a, b"`
+		Ports  []int             `cli:"--ports" default:"80,443"`
+		Labels map[string]string `cli:"--labels" default:"k1=v1,k2=v2"`
+		Files  []string          `cli:"files" default:"f1,f2"`
+	}
+
+	resetDefaultApp()
+	args1 := &cmdArgs{}
+	_, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, args1.Hosts)
+	assert.Equal(t, []int{80, 443}, args1.Ports)
+	assert.Equal(t, map[string]string{"k1": "v1", "k2": "v2"}, args1.Labels)
+	assert.Equal(t, []string{"f1", "f2"}, args1.Files)
+
+	// Default values are replaced by values from command line.
+	resetDefaultApp()
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-H", "x", "--hosts", "y", "--labels", "k3=v3", "f3"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x", "y"}, args2.Hosts)
+	assert.Equal(t, []int{80, 443}, args2.Ports)
+	assert.Equal(t, map[string]string{"k3": "v3"}, args2.Labels)
+	assert.Equal(t, []string{"f3"}, args2.Files)
+
+	// Default values from WithDefaults are also replaced.
+	resetDefaultApp()
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithDefaults(map[string]any{"hosts": []string{"c"}}),
+		WithArgs([]string{"-H", "x"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"x"}, args3.Hosts)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[default: a, b]\n")
+	assert.Contains(t, got, "[default: k1=v1,k2=v2]\n")
+	assert.Contains(t, got, "[default: f1,f2]\n")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Ports []int `cli:"--ports" default:"80,abc"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestParse_EnumTag(t *testing.T) {
+	type cmdArgs struct {
+		Format string   `cli:"-f, --format, Output format" enum:"json:JSON output, yaml:YAML output, text"`
+		Level  string   `cli:"-l, --level, Log level" default:"info" enum:"debug,info,warn"`
+		Tags   []string `cli:"--tags" enum:"a,b,c"`
+		Action string   `cli:"action" enum:"start,stop"`
+	}
+
+	resetDefaultApp()
+	args := &cmdArgs{}
+	_, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"start", "-f", "yaml", "--tags", "a", "--tags", "c"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "yaml", args.Format)
+	assert.Equal(t, "info", args.Level)
+	assert.Equal(t, []string{"a", "c"}, args.Tags)
+
+	cases := []struct {
+		args  []string
+		value string
+		want  string
+	}{
+		{[]string{"start", "-f", "xml"}, "xml", "value for flag '-format' is invalid, must be one of: json, yaml, text"},
+		{[]string{"start", "--tags", "a", "--tags", "d"}, "d", "value for flag '-tags' is invalid, must be one of: a, b, c"},
+		{[]string{"jump"}, "jump", "value for argument 'action' is invalid, must be one of: start, stop"},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		resetDefaultApp()
+		defaultApp.getFlagSet().SetOutput(&buf)
+		_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError), WithArgs(c.args))
+		var enumErr *InvalidEnumError
+		if assert.True(t, errors.As(err, &enumErr), "args: %v", c.args) {
+			assert.Equal(t, c.value, enumErr.Value)
+			assert.Equal(t, c.want, err.Error())
+		}
+	}
+
+	// WithEnums overrides the enum tag.
+	resetDefaultApp()
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithEnums(map[string][]string{"format": {"xml"}}),
+		WithArgs([]string{"start", "-f", "xml"}))
+	assert.Nil(t, err)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[valid: json, yaml, text]\n")
+	assert.Contains(t, got, "  json  JSON output\n")
+	assert.Contains(t, got, "  yaml  YAML output\n")
+	assert.Contains(t, got, "[valid: debug, info, warn]\n")
+	assert.NotContains(t, got, "  text  ")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Level string `cli:"--level" default:"trace" enum:"debug,info"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestParse_SliceSeparator(t *testing.T) {
+	type cmdArgs struct {
+		Queries []string          `cli:"-q, --query" env:"TEST_QUERIES" default:"select 1, 2" sep:"-"`
+		Columns []string          `cli:"--columns" env:"TEST_COLUMNS" default:"a;b" sep:";"`
+		Labels  map[string]string `cli:"--labels" env:"TEST_LABELS" sep:"|"`
+		Tags    []string          `cli:"--tags" env:"TEST_TAGS"`
+	}
+
+	resetDefaultApp()
+	args := &cmdArgs{}
+	_, err := Parse(args, WithErrorHandling(flag.ContinueOnError), WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"select 1, 2"}, args.Queries)
+	assert.Equal(t, []string{"a", "b"}, args.Columns)
+
+	resetDefaultApp()
+	os.Setenv("TEST_QUERIES", "select a, b")
+	os.Setenv("TEST_COLUMNS", "x,y;z")
+	os.Setenv("TEST_LABELS", "k1=a,b|k2=c")
+	os.Setenv("TEST_TAGS", "t1,t2")
+	args = &cmdArgs{}
+	_, err = Parse(args, WithErrorHandling(flag.ContinueOnError), WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"select a, b"}, args.Queries)
+	assert.Equal(t, []string{"x,y", "z"}, args.Columns)
+	assert.Equal(t, map[string]string{"k1": "a,b", "k2": "c"}, args.Labels)
+	assert.Equal(t, []string{"t1", "t2"}, args.Tags)
+
+	resetDefaultApp()
+	args = &cmdArgs{}
+	_, err = Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-q", "select 1, 2", "-q", "select 3", "--columns", "c;d",
+			"--columns", "e", "--labels", "k=v1,v2|x=y", "--tags", "a,b"}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"select 1, 2", "select 3"}, args.Queries)
+	assert.Equal(t, []string{"c", "d", "e"}, args.Columns)
+	assert.Equal(t, map[string]string{"k": "v1,v2", "x": "y"}, args.Labels)
+	assert.Equal(t, []string{"a,b"}, args.Tags)
+
+	// Elements of list and map from config file are not split.
+	file := writeTestConfigFile(t, "app.json", `{
+		"columns": ["a;b", "c"],
+		"labels": {"k": "v1|v2"},
+		"tags": ["t1,t2"]
+	}`)
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	args = &cmdArgs{}
+	_, err = Parse(args, WithErrorHandling(flag.ContinueOnError), WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a;b", "c"}, args.Columns)
+	assert.Equal(t, map[string]string{"k": "v1|v2"}, args.Labels)
+	assert.Equal(t, []string{"t1,t2"}, args.Tags)
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Name string `cli:"--name" sep:";"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
+
+func TestParse_PrefixedNestedStruct(t *testing.T) {
+	type dbOptions struct {
+		Host string `cli:"-H, --host, Database host" env:"DB_HOST" default:"localhost"`
+		Port int    `cli:"--port, Database port" default:"3306"`
+	}
+	type cmdArgs struct {
+		Source  dbOptions `prefix:"src-"`
+		Target  dbOptions `prefix:"dst-" envprefix:"TARGET_" heading:"Target Database"`
+		Verbose bool      `cli:"-v, --verbose"`
+	}
+
+	resetDefaultApp()
+	os.Setenv("SRC_DB_HOST", "10.0.0.1")
+	os.Setenv("TARGET_DB_HOST", "10.0.0.2")
+	args := &cmdArgs{}
+	fs, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--src-port", "3307", "--dst-host", "10.0.0.3", "-v"}))
+	assert.Nil(t, err)
+	assert.Equal(t, dbOptions{Host: "10.0.0.1", Port: 3307}, args.Source)
+	assert.Equal(t, dbOptions{Host: "10.0.0.3", Port: 3306}, args.Target)
+	assert.True(t, args.Verbose)
+	assert.Nil(t, fs.Lookup("H"))
+	assert.NotNil(t, fs.Lookup("src-host"))
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	want := `Usage:
+  mcli.test [flags]
+
+Flags:
+  -v, --verbose
+
+Source Flags:
+      --src-host <string>    Database host
+                             [default: "localhost"]
+                             [env: SRC_DB_HOST]
+      --src-port <int>       Database port
+                             [default: 3306]
+
+Target Database:
+      --dst-host <string>    Database host
+                             [default: "localhost"]
+                             [env: TARGET_DB_HOST]
+      --dst-port <int>       Database port
+                             [default: 3306]
+
+`
+	assert.Equal(t, want, buf.String())
+}
+
 func TestApp_AliasCommand(t *testing.T) {
 	resetDefaultApp()
 	Add("cmd1", dummyCmd, "dummy cmd1")
@@ -1050,6 +1637,217 @@ func TestApp_FunctionWithContext(t *testing.T) {
 	assert.Contains(t, got, "dummy cmd1")
 }
 
+func TestApp_RunE(t *testing.T) {
+	errCmd := errors.New("command error")
+	newTestApp := func(buf *bytes.Buffer) *App {
+		type cmdArgs struct {
+			Name string `cli:"#R, -n, --name, The name"`
+		}
+		app := NewApp()
+		app.Add("cmd1", NewCommandE(func(ctx *Context, args *cmdArgs) error {
+			return errCmd
+		}), "A cmd1 description")
+		app.Add("cmd2", func() error {
+			return errCmd
+		}, "A cmd2 description")
+		app.Add("cmd3", func(ctx *Context) error {
+			return nil
+		}, "A cmd3 description")
+		app.getFlagSet().SetOutput(buf)
+		return app
+	}
+
+	var buf bytes.Buffer
+
+	err := newTestApp(&buf).RunE("cmd1", "-n", "abc")
+	assert.Equal(t, errCmd, err)
+
+	err = newTestApp(&buf).RunE("cmd2")
+	assert.Equal(t, errCmd, err)
+
+	err = newTestApp(&buf).RunE("cmd3")
+	assert.Nil(t, err)
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("cmd1")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "flag is required but not set: -name")
+	assert.Contains(t, buf.String(), "Usage:\n")
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("cmd1", "-x")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "flag provided but not defined: -x")
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("cmd1", "-h")
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Usage:\n")
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("cmd9")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "'cmd9' is not a valid command")
+}
+
+func TestApp_ReportAllErrors(t *testing.T) {
+	type cmdArgs struct {
+		Name  string `cli:"#R, -n, --name, The name"`
+		Mode  string `cli:"#R, -m, --mode, The mode"`
+		Level string `cli:"-l, --level, The level"`
+		Count int    `cli:"count, The count"`
+		Text  string `cli:"#R, text, The text"`
+	}
+	enums := map[string][]string{"level": {"debug", "info"}}
+
+	resetDefaultApp()
+	defaultApp.ReportAllErrors = true
+	var buf bytes.Buffer
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err := Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError), WithEnums(enums),
+		WithArgs([]string{"abc", "-l", "warn"}))
+	assert.NotNil(t, err)
+
+	var errs ValidationErrors
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 5)
+	got := buf.String()
+	for _, x := range []string{
+		`invalid value "abc" for argument 'count'`,
+		"flag is required but not set: -mode",
+		"flag is required but not set: -name",
+		"argument is required but not given: text",
+		"value for flag '-level' is invalid, must be one of: debug, info",
+	} {
+		assert.Contains(t, err.Error(), x)
+		assert.Contains(t, got, x)
+	}
+	assert.Equal(t, 1, strings.Count(got, "Usage:\n"))
+	assert.Less(t, strings.Index(got, "argument is required"), strings.Index(got, "Usage:\n"))
+
+	resetDefaultApp()
+	defaultApp.ReportAllErrors = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError), WithEnums(enums),
+		WithArgs([]string{"123", "-n", "a", "-m", "b", "text"}))
+	assert.Nil(t, err)
+
+	resetDefaultApp()
+	defaultApp.ReportAllErrors = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError), WithEnums(enums),
+		WithArgs([]string{"123", "-n", "a", "text"}))
+	var missingFlagErr *MissingFlagError
+	assert.True(t, errors.As(err, &missingFlagErr))
+	assert.Equal(t, "mode", missingFlagErr.Flag)
+
+	type cmdArgs2 struct {
+		Retry   int           `cli:"-r, --retry"`
+		Timeout time.Duration `cli:"-t, --timeout"`
+		Name    string        `cli:"#R, -n, --name"`
+	}
+	resetDefaultApp()
+	defaultApp.ReportAllErrors = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs2{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-r", "abc", "--timeout=1x"}))
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 3)
+	var invalidValueErr *InvalidValueError
+	assert.True(t, errors.As(errs[0], &invalidValueErr))
+	assert.Equal(t, "retry", invalidValueErr.Name)
+	assert.Equal(t, "abc", invalidValueErr.Value)
+	assert.Contains(t, errs[1].Error(), `invalid value "1x" for flag '-timeout'`)
+	assert.Contains(t, errs[2].Error(), "flag is required but not set: -name")
+
+	// An invalid command stops parsing, it is reported together with
+	// the errors collected before it.
+	type cmdArgs3 struct {
+		Count int `cli:"-c, --count" env:"TEST_MCLI_COUNT"`
+	}
+	resetDefaultApp()
+	os.Setenv("TEST_MCLI_COUNT", "abc")
+	app := NewApp()
+	app.ReportAllErrors = true
+	app.Add("cmd1", func(ctx *Context) {
+		ctx.Parse(&cmdArgs3{})
+	}, "cmd1")
+	app.Add("cmd2", dummyCmdWithContext, "cmd2")
+	buf.Reset()
+	app.getFlagSet().SetOutput(&buf)
+	err = app.RunE("cmd1", "cmd2")
+	errs = nil
+	assert.True(t, errors.As(err, &errs))
+	assert.Len(t, errs, 2)
+	assert.True(t, errors.As(err, &invalidValueErr))
+	assert.Equal(t, "count", invalidValueErr.Name)
+	var invalidCmdErr *InvalidCommandError
+	assert.True(t, errors.As(err, &invalidCmdErr))
+	assert.Equal(t, "cmd1 cmd2", invalidCmdErr.Command)
+	got = buf.String()
+	assert.Contains(t, got, `invalid value "abc" for flag '-count' from env TEST_MCLI_COUNT`)
+	assert.Contains(t, got, "'cmd1 cmd2' is not a valid command.")
+
+	// Is and As walk the errors, without relying on Go 1.20 Unwrap.
+	invalidCmdErr = nil
+	assert.True(t, errs.As(&invalidCmdErr))
+	assert.Equal(t, "cmd1 cmd2", invalidCmdErr.Command)
+	assert.True(t, errs.Is(invalidValueErr))
+	assert.False(t, errs.Is(flag.ErrHelp))
+}
+
+func TestParse_FlagSetVisit(t *testing.T) {
+	type cmdArgs struct {
+		A  bool     `cli:"-a, --a-flag"`
+		B  *bool    `cli:"-b, --b-flag"`
+		C  string   `cli:"-c, --c-flag" env:"TEST_MCLI_C_FLAG"`
+		D  []string `cli:"-d, --d-flag"`
+		E  int      `cli:"-e, --e-flag"`
+		F1 string   `cli:"f1"`
+	}
+
+	resetDefaultApp()
+	os.Setenv("TEST_MCLI_C_FLAG", "cval")
+	args := &cmdArgs{}
+	fs, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-b", "-d", "x", "--d-flag=y", "arg1"}))
+	assert.Nil(t, err)
+	assert.NotNil(t, args.B)
+	assert.True(t, *args.B)
+	assert.Equal(t, "cval", args.C)
+	assert.Equal(t, []string{"x", "y"}, args.D)
+	assert.Equal(t, "arg1", args.F1)
+	assert.True(t, fs.Parsed())
+	assert.Equal(t, []string{"arg1"}, fs.Args())
+
+	var visited []string
+	fs.Visit(func(f *flag.Flag) {
+		visited = append(visited, f.Name)
+	})
+	assert.Equal(t, []string{"b", "b-flag", "c", "c-flag", "d", "d-flag"}, visited)
+	assert.Equal(t, true, fs.Lookup("b-flag").Value.(flag.Getter).Get())
+	assert.Equal(t, `["x","y"]`, fs.Lookup("d-flag").Value.String())
+
+	// Set a flag after parsing works as package "flag".
+	assert.Nil(t, fs.Set("e", "3"))
+	assert.Equal(t, 3, args.E)
+	assert.NotNil(t, fs.Set("e-flag", "abc"))
+
+	resetDefaultApp()
+	var buf bytes.Buffer
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-e"}))
+	assert.Equal(t, "flag needs an argument: -e", err.Error())
+	assert.Contains(t, buf.String(), "flag needs an argument: -e\nUsage:\n")
+
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"---e"}))
+	assert.Equal(t, "bad flag syntax: ---e", err.Error())
+}
+
 func TestApp_printSuggestion(t *testing.T) {
 	resetDefaultApp()
 	defaultApp.getFlagSet().Init("", flag.ContinueOnError)
@@ -1244,3 +2042,79 @@ func TestApply(t *testing.T) {
 		assert.Equal(t, true, flag.required)
 	})
 }
+
+func TestNewCommandE_ArgsError(t *testing.T) {
+	type cmdArgs struct {
+		Name  string `cli:"#R, -n, --name"`
+		Level string `cli:"--level" enum:"debug,info"`
+	}
+	called := false
+	newTestApp := func(buf *bytes.Buffer) *App {
+		called = false
+		app := NewApp()
+		app.Add("cmd1", NewCommandE(func(ctx *Context, args *cmdArgs) error {
+			called = true
+			return nil
+		}, WithErrorHandling(flag.ContinueOnError)), "A cmd1 description")
+		app.getFlagSet().SetOutput(buf)
+		return app
+	}
+
+	var buf bytes.Buffer
+	err := newTestApp(&buf).RunE("cmd1")
+	var missingErr *MissingFlagError
+	assert.True(t, errors.As(err, &missingErr))
+	assert.False(t, called)
+
+	err = newTestApp(&buf).RunE("cmd1", "-n", "a", "--level", "warn")
+	var enumErr *InvalidEnumError
+	assert.True(t, errors.As(err, &enumErr))
+	assert.False(t, called)
+
+	err = newTestApp(&buf).RunE("cmd1", "-h")
+	assert.Nil(t, err)
+	assert.False(t, called)
+
+	err = newTestApp(&buf).RunE("cmd1", "-n", "a")
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	// The parsing error is already printed, App.Run doesn't print it
+	// again, and exits with code 2.
+	buf.Reset()
+	app := newTestApp(&buf)
+	err = app.RunE("cmd1", "-n", "a", "--level", "warn")
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, app.reportRunError(err))
+	assert.Equal(t, 1, strings.Count(buf.String(), err.Error()))
+
+	// An error returned by the command is printed, and exits with code 1.
+	buf.Reset()
+	app = NewApp()
+	app.Add("cmd1", NewCommandE(func(ctx *Context, args *cmdArgs) error {
+		return errors.New("command failed")
+	}, WithErrorHandling(flag.ContinueOnError)), "A cmd1 description")
+	app.getFlagSet().SetOutput(&buf)
+	err = app.RunE("cmd1", "-n", "a")
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, app.reportRunError(err))
+	assert.Equal(t, "command failed\n", buf.String())
+}
+
+func TestApp_RunE_SwallowedExit(t *testing.T) {
+	type cmdArgs struct {
+		Name string `cli:"#R, -n, --name"`
+	}
+	var buf bytes.Buffer
+	app := NewApp()
+	app.Add("cmd1", func(ctx *Context) error {
+		defer func() { recover() }()
+		ctx.Parse(&cmdArgs{})
+		return nil
+	}, "A cmd1 description")
+	app.getFlagSet().SetOutput(&buf)
+
+	err := app.RunE("cmd1")
+	var missingErr *MissingFlagError
+	assert.True(t, errors.As(err, &missingErr))
+}
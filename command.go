@@ -1,6 +1,8 @@
 package mcli
 
 import (
+	"errors"
+	"flag"
 	"reflect"
 	"sort"
 	"strings"
@@ -14,7 +16,7 @@ type Command struct {
 	Hidden      bool
 
 	app *App
-	f   func()
+	f   func() error
 
 	cmdOpts   []CmdOpt
 	parseOpts []ParseOpt
@@ -27,6 +29,50 @@ type Command struct {
 	isCompletion bool
 }
 
+// isHidden tells whether the command is hidden in help and completion,
+// an experimental command is hidden unless experimental commands are enabled.
+func (p *Command) isHidden() bool {
+	if p.Hidden {
+		return true
+	}
+	return p.app != nil && p.getLifecycleOpts().experimental && !p.app.isExperimentalEnabled()
+}
+
+// getLifecycleOpts returns the command options, an alias command inherits
+// the options Deprecated and Experimental from the target command.
+func (p *Command) getLifecycleOpts() cmdOptions {
+	opts := newCmdOptions(p.cmdOpts...)
+	if p.AliasOf == "" || p.app == nil {
+		return opts
+	}
+	if target := p.app.cmdMap[p.AliasOf]; target != nil {
+		targetOpts := newCmdOptions(target.cmdOpts...)
+		if !opts.deprecated && targetOpts.deprecated {
+			opts.deprecated = true
+			opts.deprecatedMsg = targetOpts.deprecatedMsg
+		}
+		opts.experimental = opts.experimental || targetOpts.experimental
+	}
+	return opts
+}
+
+// lifecycleTag returns the tags to show after the command name in help,
+// e.g. " (HIDDEN)", " (DEPRECATED)".
+func (p *Command) lifecycleTag() string {
+	var tag string
+	opts := p.getLifecycleOpts()
+	if p.Hidden {
+		tag += " (HIDDEN)"
+	}
+	if opts.deprecated {
+		tag += " (DEPRECATED)"
+	}
+	if opts.experimental {
+		tag += " (EXPERIMENTAL)"
+	}
+	return tag
+}
+
 // NewCommand accepts a typed function and returns a Command.
 // The type parameter T must be a struct, else it panics.
 // When the command is matched, mcli will parse "args" and pass it to f,
@@ -36,6 +82,29 @@ type Command struct {
 // flags and arguments.
 // In case you want to get the parsed flag.FlagSet, check Context.FlagSet.
 func NewCommand[T any](f func(ctx *Context, args *T), opts ...ParseOpt) *Command {
+	return newTypedCommand(func(ctx *Context, args *T) error {
+		f(ctx, args)
+		return nil
+	}, false, opts...)
+}
+
+// NewCommandE is similar to NewCommand, but f returns an error.
+// The error returned by f is returned by App.RunE, which gives the
+// program a chance to decide its exit code and run deferred cleanups.
+// When the command is run by App.Run, a non-nil error is printed
+// and the program exits with code 1.
+//
+// Unlike NewCommand, if option `WithErrorHandling(flag.ContinueOnError)`
+// is used, f is not called when an error occurs in parsing flags and
+// arguments, the error is returned instead. The error is already
+// printed with usage, App.Run does not print it again, and exits with
+// code 2, same as other parsing failures.
+// When help is requested, f is not called and nil is returned.
+func NewCommandE[T any](f func(ctx *Context, args *T) error, opts ...ParseOpt) *Command {
+	return newTypedCommand(f, true, opts...)
+}
+
+func newTypedCommand[T any](f func(ctx *Context, args *T) error, returnArgsErr bool, opts ...ParseOpt) *Command {
 	if reflect.TypeOf(*(new(T))).Kind() != reflect.Struct {
 		panic("mcli: NewCommand args type T must be a struct")
 	}
@@ -43,16 +112,30 @@ func NewCommand[T any](f func(ctx *Context, args *T), opts ...ParseOpt) *Command
 		cmdOpts:   []CmdOpt{EnableFlagCompletion()},
 		parseOpts: opts,
 	}
-	cmd.f = func() {
+	cmd.f = func() error {
 		ctx := newContext(cmd.app)
 		args := new(T)
-		cmd.app.parseArgs(args, cmd.parseOpts...)
-		f(ctx, args)
+		_, err := cmd.app.parseArgs(args, cmd.parseOpts...)
+		if err != nil && returnArgsErr {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil
+			}
+			cmd.app.getParsingContext().flagErrReturned = true
+			return err
+		}
+		return f(ctx, args)
 	}
 	return cmd
 }
 
 func newUntypedCommand(f func(), opts ...CmdOpt) *Command {
+	return newUntypedCommandE(func() error {
+		f()
+		return nil
+	}, opts...)
+}
+
+func newUntypedCommandE(f func() error, opts ...CmdOpt) *Command {
 	return &Command{
 		f:       f,
 		cmdOpts: opts,
@@ -60,12 +143,19 @@ func newUntypedCommand(f func(), opts ...CmdOpt) *Command {
 }
 
 func newUntypedCtxCommand(f func(*Context), opts ...CmdOpt) *Command {
+	return newUntypedCtxCommandE(func(ctx *Context) error {
+		f(ctx)
+		return nil
+	}, opts...)
+}
+
+func newUntypedCtxCommandE(f func(*Context) error, opts ...CmdOpt) *Command {
 	cmd := &Command{
 		cmdOpts: opts,
 	}
-	cmd.f = func() {
+	cmd.f = func() error {
 		ctx := newContext(cmd.app)
-		f(ctx)
+		return f(ctx)
 	}
 	return cmd
 }
@@ -157,6 +247,49 @@ func (p commands) search(ctx *parsingContext, cmdArgs []string) (hasSub bool) {
 	return
 }
 
+// expandAbbrev expands the unambiguous prefixes of command words in args
+// to the full words, e.g. "iss cre" to "issue create", a word which
+// equals to a command word exactly is always kept.
+// It stops at the first flag, or the first word which matches no command,
+// and returns the candidate commands if a word is ambiguous.
+func (p commands) expandAbbrev(args []string) (out []string, candidates []string) {
+	out = append([]string(nil), args...)
+	for i, arg := range out {
+		if strings.HasPrefix(arg, "-") {
+			break
+		}
+		parent := strings.Join(out[:i], " ")
+		var words []string
+		exact := false
+		for _, cmd := range p {
+			names := strings.Fields(cmd.Name)
+			if len(names) <= i || strings.Join(names[:i], " ") != parent {
+				continue
+			}
+			if names[i] == arg {
+				exact = true
+				break
+			}
+			if !cmd.isHidden() && strings.HasPrefix(names[i], arg) && !contains(words, names[i]) {
+				words = append(words, names[i])
+			}
+		}
+		switch {
+		case exact:
+			continue
+		case len(words) == 1:
+			out[i] = words[0]
+			continue
+		case len(words) > 1:
+			for _, w := range words {
+				candidates = append(candidates, strings.TrimSpace(parent+" "+w))
+			}
+		}
+		break
+	}
+	return out, candidates
+}
+
 func (p commands) listSubCommandsToPrint(name string, showHidden bool) (sub commands) {
 	sub = p._listSubCommandsToPrint(name, showHidden, false)
 	if len(sub) > 10 {
@@ -172,7 +305,7 @@ func (p commands) _listSubCommandsToPrint(name string, showHidden, onlyNextLevel
 	for _, cmd := range p {
 		if cmd.Name != name && strings.HasPrefix(cmd.Name, name) {
 			// Don't print hidden commands.
-			if cmd.Hidden && !showHidden {
+			if cmd.isHidden() && !showHidden {
 				continue
 			}
 			if onlyNextLevel {
@@ -208,7 +341,7 @@ func (p commands) suggest(name string) []string {
 	var levenshteinSuggestions []string
 	var prefixSuggestions []withDistance
 	for _, cmd := range p {
-		if !cmd.Hidden {
+		if !cmd.isHidden() {
 			levenshteinDistance := ld(name, cmd.Name, true)
 			isPrefix := strings.HasPrefix(strings.ToLower(cmd.Name), strings.ToLower(name))
 			if levenshteinDistance <= minDistance {
@@ -1,6 +1,9 @@
 package mcli
 
 import (
+	"bytes"
+	"errors"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -83,3 +86,133 @@ func TestCommandsGrouping(t *testing.T) {
 	assert.Equal(t, "Other Commands", cmdGroups[3].category)
 	assert.Len(t, cmdGroups[3].commands, 2)
 }
+
+func TestApp_CommandLifecycle(t *testing.T) {
+	var called []string
+	newTestApp := func(buf *bytes.Buffer) *App {
+		called = nil
+		app := NewApp()
+		app.ExperimentalEnv = "TEST_MCLI_EXPERIMENTAL"
+		app.Add("issue create", func() { called = append(called, "issue create") }, "Create an issue")
+		app.Add("issue new", func() { called = append(called, "issue new") }, "Create an issue",
+			Deprecated(`use "issue create" instead`))
+		app.Add("issue ai", func() { called = append(called, "issue ai") }, "Triage issues",
+			Experimental())
+		app.AddAlias("issue old", "issue new")
+		app.AddAlias("triage", "issue ai")
+		app.getFlagSet().SetOutput(buf)
+		return app
+	}
+
+	var buf bytes.Buffer
+	err := newTestApp(&buf).RunE("issue", "new")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"issue new"}, called)
+	assert.Equal(t, "Warning: command 'issue new' is deprecated, use \"issue create\" instead\n", buf.String())
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("issue")
+	assert.Nil(t, err)
+	got := buf.String()
+	assert.Contains(t, got, "new (DEPRECATED)    Create an issue\n")
+	assert.NotContains(t, got, "ai")
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("issue", "ai")
+	var expErr *ExperimentalCommandError
+	assert.True(t, errors.As(err, &expErr))
+	assert.Equal(t, "'issue ai' is an experimental command, enable it by flag '--mcli-experimental' "+
+		"or env TEST_MCLI_EXPERIMENTAL=1", err.Error())
+	assert.Nil(t, called)
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("issue", "ai", "--mcli-experimental")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"issue ai"}, called)
+	assert.Equal(t, "Warning: command 'issue ai' is experimental, "+
+		"it may change or be removed in future releases\n", buf.String())
+
+	// Aliases inherit the lifecycle options of the target commands.
+	buf.Reset()
+	err = newTestApp(&buf).RunE("issue", "old")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"issue new"}, called)
+	assert.Equal(t, "Warning: command 'issue old' is deprecated, use \"issue create\" instead\n", buf.String())
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("triage")
+	assert.True(t, errors.As(err, &expErr))
+	assert.Equal(t, "triage", expErr.Command)
+	assert.Nil(t, called)
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE()
+	assert.Nil(t, err)
+	assert.NotContains(t, buf.String(), "triage")
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("triage", "--mcli-experimental")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"issue ai"}, called)
+	assert.Equal(t, "Warning: command 'triage' is experimental, "+
+		"it may change or be removed in future releases\n", buf.String())
+
+	os.Setenv("TEST_MCLI_EXPERIMENTAL", "1")
+	defer os.Unsetenv("TEST_MCLI_EXPERIMENTAL")
+	buf.Reset()
+	app := newTestApp(&buf)
+	err = app.RunE("issue")
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "ai (EXPERIMENTAL)    Triage issues\n")
+	assert.Contains(t, app.cmds.suggest("issue a"), "issue ai")
+}
+
+func TestApp_CommandAbbreviation(t *testing.T) {
+	var called []string
+	newTestApp := func(buf *bytes.Buffer) *App {
+		called = nil
+		app := NewApp()
+		app.AllowCommandAbbreviation = true
+		for _, name := range []string{"issue create", "issue close", "install", "st", "status"} {
+			app.Add(name, func(ctx *Context) {
+				called = append(called, ctx.Command.Name)
+			}, "A command")
+		}
+		app.getFlagSet().SetOutput(buf)
+		return app
+	}
+
+	var buf bytes.Buffer
+	err := newTestApp(&buf).RunE("iss", "cr", "bug")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"issue create"}, called)
+
+	err = newTestApp(&buf).RunE("st")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"st"}, called)
+
+	err = newTestApp(&buf).RunE("stat")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"status"}, called)
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("issue", "c")
+	var cmdErr *InvalidCommandError
+	require.True(t, errors.As(err, &cmdErr))
+	assert.True(t, cmdErr.Ambiguous)
+	assert.Equal(t, []string{"issue close", "issue create"}, cmdErr.Suggestions)
+	assert.Contains(t, err.Error(), "'issue c' is an ambiguous command.")
+	assert.Contains(t, buf.String(), "Did you mean this?\n    \tissue close\n    \tissue create\n")
+	assert.Nil(t, called)
+
+	buf.Reset()
+	err = newTestApp(&buf).RunE("i")
+	require.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, []string{"install", "issue"}, cmdErr.Suggestions)
+
+	app := newTestApp(&buf)
+	app.AllowCommandAbbreviation = false
+	err = app.RunE("iss", "cr")
+	require.True(t, errors.As(err, &cmdErr))
+	assert.False(t, cmdErr.Ambiguous)
+}
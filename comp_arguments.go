@@ -56,6 +56,8 @@ func (c *compContextImpl) ArgPrefix() string {
 // WithArgCompFuncs specifies completion functions to complete flag values
 // or positional arguments.
 // Key of funcMap should be a flag name in form "-flag" or a positional arg name "arg1".
+// Flags and arguments without a completion function are completed with
+// their valid enum values, or "true" and "false" for boolean values.
 func WithArgCompFuncs(funcMap map[string]ArgCompletionFunc) ParseOpt {
 	copyMap := make(map[string]ArgCompletionFunc)
 	for name, x := range funcMap {
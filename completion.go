@@ -72,6 +72,9 @@ func (p *App) setupCompletionCtx(userArgs []string, completionShell string) {
 		p.completionCtx.lastArg = userArgs[n-1]
 		userArgs = userArgs[:n-1]
 	}
+	if p.AllowCommandAbbreviation {
+		userArgs, _ = p.cmds.expandAbbrev(userArgs)
+	}
 	p.completionCtx.userArgs = userArgs
 	p.completionCtx.cmdArgs = &userArgs
 }
@@ -86,6 +89,11 @@ func (p *App) doAutoCompletion(userArgs []string) {
 	ctx := p.getParsingContext()
 	tree := p.parseCompletionCmdTree()
 
+	// Complete words are expanded by setupCompletionCtx when command
+	// abbreviation is enabled, the last word is kept to be completed.
+	if p.AllowCommandAbbreviation && len(userArgs) > 0 {
+		userArgs = append(clip(p.completionCtx.userArgs), p.completionCtx.lastArg)
+	}
 	cmdNames := userArgs
 	hasFlag := false
 	for i, x := range userArgs {
@@ -112,6 +120,10 @@ func (p *App) doAutoCompletion(userArgs []string) {
 			cmdWord = leftArgs[0]
 		}
 		suggestions := tree.suggestedSubCommands(p, cmdWord)
+		isTopLevel := len(leftArgs) == len(cmdNames)
+		if isTopLevel && p.EnablePlugins {
+			suggestions = append(suggestions, p.suggestedPlugins(cmdWord)...)
+		}
 		if len(suggestions) > 0 {
 			printLines(p.completionCtx.out, suggestions)
 			return
@@ -168,8 +180,8 @@ func (p *App) checkLastArgForCompletion() {
 					} else {
 						flagName := cleanFlagName(secondLastWord)
 						for _, f := range pCtx.flags {
-							if f.name == flagName || f.short == flagName {
-								if f.isBoolean() {
+							if f.hasName(flagName) {
+								if f.isBoolFlag() {
 									// Boolean flags do not accept values,
 									// the user is requesting a positional arg.
 									compCtx.wantPositionalArg = true
@@ -209,8 +221,8 @@ func (p *App) checkLastArgForCompletion() {
 				} else {
 					flagName := cleanFlagName(lastWord)
 					for _, f := range pCtx.flags {
-						if f.name == flagName || f.short == flagName {
-							if f.isBoolean() {
+						if f.hasName(flagName) {
+							if f.isBoolFlag() {
 								// Boolean flags do not accept values,
 								// the user is requesting a positional arg.
 								compCtx.wantPositionalArg = true
@@ -259,19 +271,27 @@ func (p *App) parseArgsForCompletion() {
 		return
 	}
 
+	// Take the arguments after "--" for the pass-through argument.
+	cmdArgs = ctx.takePassthroughArgs(cmdArgs)
+
 	// Expand the posix-style single-token-multiple-values flags.
 	if p.Options.AllowPosixSTMO {
 		cmdArgs = expandSTMOFlags(ctx.flagMap, cmdArgs)
 	}
 
-	if err = fs.Parse(cmdArgs); err != nil {
+	// Move flags interspersed with positional arguments to front.
+	if p.Options.AllowInterspersedFlags {
+		cmdArgs = permuteFlags(ctx.flagMap, cmdArgs)
+	}
+
+	if err = ctx.parseFlagSet(cmdArgs); err != nil {
 		return
 	}
 	nonflagArgs, err := ctx.parseNonflags()
 	if err != nil {
 		return
 	}
-	tidyFlags(fs, ctx.flags, nonflagArgs)
+	setFlagSetArgs(fs, nonflagArgs)
 }
 
 func (p *App) parseCompletionCmdTree() *cmdTree {
@@ -357,7 +377,7 @@ func (t *cmdTree) suggestedSubCommands(app *App, cmdWord string) []string {
 		if sub.Cmd == nil && len(sub.SubCmds) == 0 {
 			continue
 		}
-		if sub.Cmd != nil && (sub.Cmd.isCompletion || sub.Cmd.Hidden) {
+		if sub.Cmd != nil && (sub.Cmd.isCompletion || sub.Cmd.isHidden()) {
 			continue
 		}
 		if !matchFunc(sub) {
@@ -442,24 +462,35 @@ func (p *App) continueFlagCompletion() {
 		}
 	}
 	isSeenFlag := func(f *_flag) bool {
-		return seenFlags[f.short] || seenFlags[f.name]
+		for name := range seenFlags {
+			if f.hasName(name) {
+				return true
+			}
+		}
+		return false
 	}
 
 	pCtx := p.getParsingContext()
 	prefixWord := compCtx.prefixWord
 	result := make([]string, 0, 16)
 	for _, flag := range pCtx.flags {
-		if flag.short != "" && strings.HasPrefix(flag.short, prefixWord) && (flag.isCompositeType() || !isSeenFlag(flag)) {
+		if flag.short != "" && strings.HasPrefix(flag.short, prefixWord) && (flag.isCompositeType() || flag.counter || !isSeenFlag(flag)) {
 			usage := getUsage(flag)
 			suggestion := p.formatCompletion("-"+flag.short, usage)
 			result = append(result, suggestion)
 		}
 
-		if flag.name != "" && strings.HasPrefix(flag.name, prefixWord) && (flag.isCompositeType() || !isSeenFlag(flag)) {
+		if flag.name != "" && strings.HasPrefix(flag.name, prefixWord) && (flag.isCompositeType() || flag.counter || !isSeenFlag(flag)) {
 			usage := getUsage(flag)
 			suggestion := p.formatCompletion("--"+flag.name, usage)
 			result = append(result, suggestion)
 		}
+
+		if flag.negatable && strings.HasPrefix(flag.negatedName(), prefixWord) && !isSeenFlag(flag) {
+			usage := getUsage(flag)
+			suggestion := p.formatCompletion("--"+flag.negatedName(), usage)
+			result = append(result, suggestion)
+		}
 	}
 	printLines(p.completionCtx.out, result)
 }
@@ -471,7 +502,7 @@ func (p *App) continueFlagValueCompletion() {
 
 	var f *_flag
 	for _, x := range pCtx.flags {
-		if x.name == flagName || x.short == flagName {
+		if x.hasName(flagName) {
 			f = x
 			break
 		}
@@ -482,13 +513,8 @@ func (p *App) continueFlagValueCompletion() {
 	compFunc := pCtx.opts.argCompFuncs["-"+f.name]
 	if compFunc == nil {
 		compFunc = pCtx.opts.argCompFuncs["-"+f.short]
-		if compFunc == nil {
-			return
-		}
 	}
-	acc := p.newArgCompletionContext()
-	compItems := compFunc(acc)
-	p.printCompletionItems(compItems)
+	p.printValueCompletion(f, compFunc)
 }
 
 func (p *App) continuePositionalArgCompletion() {
@@ -513,8 +539,15 @@ func (p *App) continuePositionalArgCompletion() {
 		return
 	}
 
-	compFunc := pCtx.opts.argCompFuncs[nf.name]
+	p.printValueCompletion(nf, pCtx.opts.argCompFuncs[nf.name])
+}
+
+// printValueCompletion prints the values to complete for a flag or
+// positional argument, by compFunc if it is given, else the default
+// values of f.
+func (p *App) printValueCompletion(f *_flag, compFunc ArgCompletionFunc) {
 	if compFunc == nil {
+		p.printCompletionItems(f.getDefaultCompletionItems(p.completionCtx.prefixWord))
 		return
 	}
 	acc := p.newArgCompletionContext()
@@ -522,6 +555,24 @@ func (p *App) continuePositionalArgCompletion() {
 	p.printCompletionItems(compItems)
 }
 
+// getDefaultCompletionItems returns the values to complete when there is
+// no completion function for f, i.e. the valid enum values with
+// descriptions from the enum tag, or "true" and "false" for a boolean value.
+// Values which don't have the given prefix are filtered out.
+func (f *_flag) getDefaultCompletionItems(prefix string) []CompletionItem {
+	values := f.enums
+	if len(values) == 0 && (f.isBoolean() || f.isBooleanPtr()) {
+		values = []string{"true", "false"}
+	}
+	var items []CompletionItem
+	for _, x := range values {
+		if strings.HasPrefix(x, prefix) {
+			items = append(items, CompletionItem{Value: x, Description: f.enumDescs[x]})
+		}
+	}
+	return items
+}
+
 func (p *App) printCompletionItems(items []CompletionItem) {
 	result := make([]string, 0, len(items))
 	for _, x := range items {
@@ -290,6 +290,39 @@ func flagArguments(ctx ArgCompletionContext) []CompletionItem {
 	}
 }
 
+func TestSuggestNegatableFlags(t *testing.T) {
+	resetDefaultApp()
+	addTestCompletionCommands()
+
+	testCmd := func() {
+		args := &struct {
+			Color bool `cli:"#N, --color, colorize the output" default:"true"`
+		}{}
+		Parse(args)
+	}
+	Add("group1 cmd3", testCmd, "A group1 cmd3 description",
+		EnableFlagCompletion())
+
+	var buf bytes.Buffer
+	defaultApp.completionCtx.out = &buf
+
+	reset := func() {
+		buf.Reset()
+		defaultApp.resetParsingContext()
+	}
+
+	reset()
+	Run("group1", "cmd3", "--", completionFlag, "zsh")
+	got1 := buf.String()
+	assert.Contains(t, got1, "--color:colorize the output\n")
+	assert.Contains(t, got1, "--no-color:colorize the output\n")
+
+	reset()
+	Run("group1", "cmd3", "--no-color", "--", completionFlag, "zsh")
+	got2 := buf.String()
+	assert.NotContains(t, got2, "color")
+}
+
 func TestSuggestFlagArgs(t *testing.T) {
 	resetDefaultApp()
 	addTestCompletionCommands()
@@ -338,6 +371,96 @@ func TestSuggestFlagArgs(t *testing.T) {
 	assert.Equal(t, flagWoWithFunction, "--a1-flag\n")
 }
 
+func TestSuggestFlagEnums(t *testing.T) {
+	resetDefaultApp()
+	addTestCompletionCommands()
+
+	testCmd := func() {
+		args := &struct {
+			Format string `cli:"-f, --format" enum:"json:JSON output, yaml:YAML output, text"`
+			Level  string `cli:"-l, --level" enum:"debug,info"`
+		}{}
+		Parse(args, WithArgCompFuncs(map[string]ArgCompletionFunc{
+			"-level": flagArguments,
+		}))
+	}
+	Add("group1 cmd3", testCmd, "A group1 cmd3 description",
+		EnableFlagCompletion())
+
+	var buf bytes.Buffer
+	defaultApp.completionCtx.out = &buf
+
+	reset := func() {
+		buf.Reset()
+		defaultApp.resetParsingContext()
+		defaultApp.resetCompletionCtx()
+	}
+
+	reset()
+	Run("group1", "cmd3", "-f", "", completionFlag, "zsh")
+	assert.Equal(t, "json:JSON output\nyaml:YAML output\ntext\n", buf.String())
+
+	reset()
+	Run("group1", "cmd3", "--format", "", completionFlag, "fish")
+	assert.Equal(t, "json\tJSON output\nyaml\tYAML output\ntext\n", buf.String())
+
+	// Completion function takes precedence.
+	reset()
+	Run("group1", "cmd3", "--level", "", completionFlag, "zsh")
+	assert.Equal(t, "alfa:description alfa\nbeta:description beta\n", buf.String())
+}
+
+func TestSuggestDefaultValues(t *testing.T) {
+	resetDefaultApp()
+	addTestCompletionCommands()
+
+	testCmd := func() {
+		args := &struct {
+			Verbose bool   `cli:"-v, --verbose"`
+			Color   bool   `cli:"#N, --color"`
+			Format  string `cli:"-f, --format" enum:"json,yaml,text"`
+			Name    string `cli:"-n, --name"`
+			Action  string `cli:"action" enum:"start:Start the server, stop:Stop the server"`
+		}{}
+		Parse(args)
+	}
+	Add("group1 cmd4", testCmd, "A group1 cmd4 description",
+		EnableFlagCompletion())
+
+	var buf bytes.Buffer
+	defaultApp.completionCtx.out = &buf
+
+	reset := func() {
+		buf.Reset()
+		defaultApp.resetParsingContext()
+		defaultApp.resetCompletionCtx()
+	}
+
+	reset()
+	Run("group1", "cmd4", "", completionFlag, "zsh")
+	assert.Equal(t, "start:Start the server\nstop:Stop the server\n", buf.String())
+
+	reset()
+	Run("group1", "cmd4", "-v", "sto", completionFlag, "bash")
+	assert.Equal(t, "stop\tStop the server\n", buf.String())
+
+	reset()
+	Run("group1", "cmd4", "-f", "y", completionFlag, "powershell")
+	assert.Equal(t, "yaml\n", buf.String())
+
+	reset()
+	Run("group1", "cmd4", "--verbose=", completionFlag, "zsh")
+	assert.Equal(t, "true\nfalse\n", buf.String())
+
+	reset()
+	Run("group1", "cmd4", "--no-color=f", completionFlag, "zsh")
+	assert.Equal(t, "false\n", buf.String())
+
+	reset()
+	Run("group1", "cmd4", "-n", "", completionFlag, "zsh")
+	assert.Equal(t, "", buf.String())
+}
+
 func commandArguments(ctx ArgCompletionContext) []CompletionItem {
 	return []CompletionItem{
 		{"value a", "description of value a"},
@@ -658,3 +781,39 @@ func TestHasCompletionFlag(t *testing.T) {
 		assert.Equal(t, shell, "unsupported")
 	})
 }
+
+func TestSuggestAbbreviatedCommands(t *testing.T) {
+	resetDefaultApp()
+	addTestCompletionCommands()
+	defaultApp.AllowCommandAbbreviation = true
+
+	testCmd := func() {
+		args := &struct {
+			Format string `cli:"-f, --format" enum:"json,yaml"`
+		}{}
+		Parse(args)
+	}
+	Add("group1 cmd4", testCmd, "A group1 cmd4 description",
+		EnableFlagCompletion())
+
+	var buf bytes.Buffer
+	defaultApp.completionCtx.out = &buf
+
+	reset := func() {
+		buf.Reset()
+		defaultApp.resetParsingContext()
+		defaultApp.resetCompletionCtx()
+	}
+
+	reset()
+	Run("gro", "", completionFlag, "powershell")
+	assert.Equal(t, "cmd1\ncmd2\ncmd3\ncmd4\n", buf.String())
+
+	reset()
+	Run("gro", "cmd", completionFlag, "powershell")
+	assert.Equal(t, "cmd1\ncmd2\ncmd3\ncmd4\n", buf.String())
+
+	reset()
+	Run("gro", "cmd4", "-f", "", completionFlag, "powershell")
+	assert.Equal(t, "json\nyaml\n", buf.String())
+}
@@ -0,0 +1,208 @@
+package mcli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ConfigDecoder decodes content of a config file to a map.
+// Nested objects in the config file must be decoded as map[string]any,
+// and lists must be decoded as []any.
+//
+// e.g. a decoder for YAML files using package "gopkg.in/yaml.v3":
+//
+//	func(data []byte) (m map[string]any, err error) {
+//		err = yaml.Unmarshal(data, &m)
+//		return
+//	}
+type ConfigDecoder func(data []byte) (map[string]any, error)
+
+const configFlagName = "config"
+
+type configFlagArgs struct {
+	Config string `cli:"--config, Load flag values from the config 'file'"`
+}
+
+func decodeJSONConfig(data []byte) (m map[string]any, err error) {
+	// Decode numbers as json.Number to keep large integers exact,
+	// e.g. 1000000 instead of 1e+06.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	err = dec.Decode(&m)
+	return
+}
+
+func (p *App) isConfigEnabled() bool {
+	return p.ConfigFile != "" || p.EnableConfigFlag
+}
+
+// getConfigFlags returns the flag "--config" to add to commands,
+// it returns nil if Options.EnableConfigFlag is not enabled.
+func (p *App) getConfigFlags() any {
+	if p.EnableConfigFlag {
+		return &configFlagArgs{}
+	}
+	return nil
+}
+
+// hasConfigFlag tells whether the flag "--config" is added to the
+// command, it is not added if global flags are disabled.
+func (ctx *parsingContext) hasConfigFlag() bool {
+	return ctx.app.EnableConfigFlag &&
+		(ctx.opts == nil || !ctx.opts.disableGlobalFlags)
+}
+
+func (p *App) getConfigDecoder(file string) ConfigDecoder {
+	ext := strings.ToLower(filepath.Ext(file))
+	if decoder := p.ConfigDecoders[ext]; decoder != nil {
+		return decoder
+	}
+	if ext == ".json" {
+		return decodeJSONConfig
+	}
+	return nil
+}
+
+// readConfigValues loads the config file and sets values to flags.
+// The config file is specified by the flag "--config" in args,
+// or Options.ConfigFile.
+func (ctx *parsingContext) readConfigValues(args []string) (err error) {
+	app := ctx.app
+	file, isFlag := app.ConfigFile, false
+	if ctx.hasConfigFlag() {
+		if value, found := findFlagValue(configFlagName, args); found {
+			file, isFlag = value, true
+		}
+	}
+	if file == "" {
+		return nil
+	}
+
+	data, e := os.ReadFile(file)
+	if e != nil {
+		// A config file specified by Options.ConfigFile is optional.
+		if os.IsNotExist(e) && !isFlag {
+			return nil
+		}
+		ctx.fail(&err, fmt.Errorf("cannot read config file: %w", e))
+		return err
+	}
+	decoder := app.getConfigDecoder(file)
+	if decoder == nil {
+		ctx.fail(&err, fmt.Errorf("unsupported config file format: %s", file))
+		return err
+	}
+	values, e := decoder(data)
+	if e != nil {
+		ctx.fail(&err, fmt.Errorf("cannot decode config file %s: %w", file, e))
+		return err
+	}
+	return ctx.applyConfigValues(file, "", values)
+}
+
+// applyConfigValues sets values to flags, the keys are flag long names.
+// A nested object is flattened by joining the keys with "-",
+// e.g. {"db": {"host": "x"}} sets the flag "--db-host",
+// except that the nested object is value of a map flag.
+func (ctx *parsingContext) applyConfigValues(file, prefix string, values map[string]any) (err error) {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		key, value := prefix+k, values[k]
+		f := ctx.getConfigFlag(key)
+		if m, ok := value.(map[string]any); ok && (f == nil || !f.isMap()) {
+			if err = ctx.applyConfigValues(file, key+"-", m); err != nil {
+				return err
+			}
+			continue
+		}
+		if f == nil || value == nil {
+			continue
+		}
+		if e := setConfigValue(ctx.getFlagSet(), f, value); e != nil {
+			ctx.fail(&err, &InvalidValueError{
+				Name:   f.name,
+				Config: file,
+				Value:  formatConfigValue(value),
+				Err:    e,
+			})
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		f.origin = ValueOrigin{Source: SourceConfigFile, ConfigFile: file}
+	}
+	return nil
+}
+
+func (ctx *parsingContext) getConfigFlag(key string) *_flag {
+	for _, f := range ctx.flags {
+		if f.configKey != "" && f.configKey == key {
+			return f
+		}
+	}
+	return nil
+}
+
+func setConfigValue(fs *flag.FlagSet, f *_flag, value any) error {
+	// Elements of list and map are set as is, without splitting.
+	var err error
+	switch x := value.(type) {
+	case []any:
+		if !f.isSlice() {
+			return fmt.Errorf("cannot use a list for type %v", f.rv.Type())
+		}
+		elems := make([]string, 0, len(x))
+		for _, elem := range x {
+			elems = append(elems, formatConfigValue(elem))
+		}
+		err = setFlagElems(fs, f, elems)
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		elems := make([]string, 0, len(x))
+		for _, k := range keys {
+			elems = append(elems, k+"="+formatConfigValue(x[k]))
+		}
+		err = setFlagElems(fs, f, elems)
+	default:
+		err = setFlag(fs, f.name, formatConfigValue(x))
+	}
+	if err != nil {
+		return err
+	}
+	// Values of slice, map and counter loaded from config file are replaced,
+	// instead of accumulated, when the flag is given from command line.
+	if f.isAccumulative() {
+		f.resetOnSet = true
+	}
+	return nil
+}
+
+// formatConfigValue formats a scalar value decoded from config file,
+// floats are formatted without exponent, e.g. 1000000 instead of 1e+06,
+// which may be decoded by a custom ConfigDecoder.
+func formatConfigValue(value any) string {
+	switch x := value.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	}
+	return fmt.Sprint(value)
+}
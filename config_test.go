@@ -0,0 +1,231 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestConfigFile(t *testing.T, name, content string) string {
+	file := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(file, []byte(content), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestParse_ConfigFile(t *testing.T) {
+	type cmdArgs struct {
+		Name   string            `cli:"-n, --name, The name" default:"dft"`
+		Level  string            `cli:"--level" default:"info" env:"TEST_MCLI_LEVEL"`
+		Port   int               `cli:"-p, --port"`
+		DBHost string            `cli:"--db-host"`
+		Tags   []string          `cli:"-t, --tags"`
+		Labels map[string]string `cli:"--labels"`
+	}
+	file := writeTestConfigFile(t, "app.json", `{
+		"name": "cfg",
+		"level": "debug",
+		"port": 8080,
+		"db": {"host": "127.0.0.1"},
+		"tags": ["a", "b"],
+		"labels": {"k1": "v1"},
+		"unknown": 1
+	}`)
+
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "cfg", args1.Name)
+	assert.Equal(t, "debug", args1.Level)
+	assert.Equal(t, 8080, args1.Port)
+	assert.Equal(t, "127.0.0.1", args1.DBHost)
+	assert.Equal(t, []string{"a", "b"}, args1.Tags)
+	assert.Equal(t, map[string]string{"k1": "v1"}, args1.Labels)
+	assert.Equal(t, "8080", fs.Lookup("p").Value.String())
+
+	// env and command line take precedence over config file.
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	os.Setenv("TEST_MCLI_LEVEL", "warn")
+	args2 := &cmdArgs{}
+	_, err = Parse(args2, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-n", "cli", "-t", "c", "--tags", "d"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "cli", args2.Name)
+	assert.Equal(t, "warn", args2.Level)
+	assert.Equal(t, []string{"c", "d"}, args2.Tags)
+
+	// A missing Options.ConfigFile is ignored.
+	resetDefaultApp()
+	defaultApp.ConfigFile = filepath.Join(t.TempDir(), "not-exist.json")
+	args3 := &cmdArgs{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, "dft", args3.Name)
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[default: \"dft\"]\n                            [config: name]\n")
+	assert.Contains(t, got, "[env: TEST_MCLI_LEVEL]\n                            [config: level]\n")
+}
+
+func TestParse_ConfigFlag(t *testing.T) {
+	type cmdArgs struct {
+		Name string `cli:"-n, --name"`
+		Port int    `cli:"-p, --port"`
+	}
+	file := writeTestConfigFile(t, "app.conf", "name=cfg\nport=8080\n")
+	decoder := func(data []byte) (map[string]any, error) {
+		m := make(map[string]any)
+		for _, line := range strings.Fields(string(data)) {
+			parts := strings.SplitN(line, "=", 2)
+			m[parts[0]] = parts[1]
+		}
+		return m, nil
+	}
+
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.ConfigDecoders = map[string]ConfigDecoder{".conf": decoder}
+	args1 := &cmdArgs{}
+	fs, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--config", file, "-p", "9090"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "cfg", args1.Name)
+	assert.Equal(t, 9090, args1.Port)
+	assert.Equal(t, file, fs.Lookup("config").Value.String())
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "Global Flags:\n      --config <file>    Load flag values from the config file\n")
+	assert.Contains(t, got, "[config: port]\n")
+
+	// The file specified by the flag must exist.
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--config=" + file + ".json"}))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "cannot read config file")
+
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--config", file}))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unsupported config file format")
+
+	badFile := writeTestConfigFile(t, "bad.json", `{"port": "abc"}`)
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--config", badFile}))
+	var invalidValueErr *InvalidValueError
+	assert.True(t, errors.As(err, &invalidValueErr))
+	assert.Equal(t, badFile, invalidValueErr.Config)
+	assert.Contains(t, err.Error(), `invalid value "abc" for flag '-port' from config file`)
+
+	// The flag "--config" is a global flag, it is not added when
+	// global flags are disabled.
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	defaultApp.getFlagSet().SetOutput(&buf)
+	args2 := &cmdArgs{}
+	fs, err = Parse(args2, WithErrorHandling(flag.ContinueOnError), DisableGlobalFlags(),
+		WithArgs([]string{"--config", file}))
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "flag provided but not defined: -config")
+	assert.Nil(t, fs.Lookup("config"))
+	assert.Equal(t, "", args2.Name)
+
+	// A user flag "--config" is allowed when global flags are disabled,
+	// else it conflicts with the global flag.
+	type cmdArgs2 struct {
+		Config string `cli:"--config"`
+	}
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	args3 := &cmdArgs2{}
+	_, err = Parse(args3, WithErrorHandling(flag.ContinueOnError), DisableGlobalFlags(),
+		WithArgs([]string{"--config", "x"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "x", args3.Config)
+
+	resetDefaultApp()
+	defaultApp.EnableConfigFlag = true
+	assert.PanicsWithValue(t, "mcli: flag '-config' conflicts with the global flag '--config', "+
+		"which may be added by Options.EnableConfigFlag", func() {
+		Parse(&cmdArgs2{}, WithErrorHandling(flag.ContinueOnError), WithArgs([]string{}))
+	})
+}
+
+func TestParse_ConfigNumbers(t *testing.T) {
+	type cmdArgs struct {
+		Size   int64            `cli:"--size"`
+		Ratio  float64          `cli:"--ratio"`
+		Ports  []int            `cli:"--ports"`
+		Limits map[string]int64 `cli:"--limits"`
+	}
+	file := writeTestConfigFile(t, "app.json", `{
+		"size": 1000000,
+		"ratio": 0.000001,
+		"ports": [8080, 10000000],
+		"limits": {"cpu": 2000000, "mem": 9007199254740993}
+	}`)
+
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	args := &cmdArgs{}
+	_, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), args.Size)
+	assert.Equal(t, 0.000001, args.Ratio)
+	assert.Equal(t, []int{8080, 10000000}, args.Ports)
+	assert.Equal(t, map[string]int64{"cpu": 2000000, "mem": 9007199254740993}, args.Limits)
+
+	// Floats decoded by a custom decoder are formatted without exponent.
+	decoder := func(data []byte) (map[string]any, error) {
+		return map[string]any{
+			"size":  float64(1e6),
+			"ports": []any{float64(1e7)},
+		}, nil
+	}
+	file = writeTestConfigFile(t, "app.conf", "")
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	defaultApp.ConfigDecoders = map[string]ConfigDecoder{".conf": decoder}
+	args = &cmdArgs{}
+	_, err = Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1000000), args.Size)
+	assert.Equal(t, []int{10000000}, args.Ports)
+}
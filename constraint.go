@@ -0,0 +1,229 @@
+package mcli
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// constraintTags are the struct tags to validate values of flags and
+// arguments, in the order of checking.
+//
+//	min, max       - limit a number, or each number of a slice or map
+//	minlen, maxlen - limit length of a string, or count of elements of a slice or map
+//	pattern        - a regular expression which a string must match,
+//	                 or each string of a slice or map must match
+var constraintTags = []string{"min", "max", "minlen", "maxlen", "pattern"}
+
+// constraint is a validation rule defined by struct tag,
+// e.g. `min:"1" max:"65535"`, `minlen:"1"`, `pattern:"^[a-z]+$"`.
+type constraint struct {
+	tag   string
+	value string
+
+	limit  reflect.Value  // min, max
+	length int            // minlen, maxlen
+	re     *regexp.Regexp // pattern
+}
+
+func parseConstraints(f *_flag, tag reflect.StructTag) error {
+	for _, name := range constraintTags {
+		value, ok := tag.Lookup(name)
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		c, err := newConstraint(f, name, value)
+		if err != nil {
+			return newProgramingError("invalid %s constraint %q for %s: %v", name, value, f.helpName(), err)
+		}
+		f.constraints = append(f.constraints, c)
+	}
+	return nil
+}
+
+func newConstraint(f *_flag, name, value string) (*constraint, error) {
+	c := &constraint{tag: name, value: value}
+	elemTyp := constraintElemType(f.rv.Type())
+	switch name {
+	case "min", "max":
+		if !isNumberKind(elemTyp.Kind()) {
+			return nil, errors.New("value type is not a number")
+		}
+		c.limit = reflect.New(elemTyp).Elem()
+		if err := applyValue(c.limit, value); err != nil {
+			return nil, err
+		}
+	case "minlen", "maxlen":
+		if elemTyp.Kind() != reflect.String && !f.isCompositeType() {
+			return nil, errors.New("value type is not a string, slice or map")
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, errors.New("length must not be negative")
+		}
+		c.length = n
+	case "pattern":
+		if elemTyp.Kind() != reflect.String {
+			return nil, errors.New("value type is not a string")
+		}
+		re, err := regexp.Compile(value)
+		if err != nil {
+			return nil, err
+		}
+		c.re = re
+	}
+	return c, nil
+}
+
+// constraintElemType returns the type of the values to check,
+// i.e. the element type of a pointer, slice or map.
+func constraintElemType(typ reflect.Type) reflect.Type {
+	switch typ.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Map:
+		return typ.Elem()
+	}
+	return typ
+}
+
+func isNumberKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// check checks the value of f, it returns the invalid value if f
+// violates the constraint.
+func (c *constraint) check(f *_flag) (invalid string, ok bool) {
+	if c.tag == "minlen" || c.tag == "maxlen" {
+		rv := f.rv
+		if rv.Kind() == reflect.Pointer {
+			rv = rv.Elem()
+		}
+		n := rv.Len()
+		if rv.Kind() == reflect.String {
+			n = utf8.RuneCountInString(rv.String())
+		}
+		if (c.tag == "minlen" && n < c.length) || (c.tag == "maxlen" && n > c.length) {
+			return formatValue(f.rv), false
+		}
+		return "", true
+	}
+
+	for _, elem := range constraintElemValues(f.rv) {
+		var valid bool
+		switch c.tag {
+		case "min":
+			valid = compareNumber(elem, c.limit) >= 0
+		case "max":
+			valid = compareNumber(elem, c.limit) <= 0
+		case "pattern":
+			valid = c.re.MatchString(elem.String())
+		}
+		if !valid {
+			return formatValue(elem), false
+		}
+	}
+	return "", true
+}
+
+// constraintElemValues returns the values to check,
+// i.e. the element of a pointer, elements of a slice, or values of a map.
+func constraintElemValues(rv reflect.Value) []reflect.Value {
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil
+		}
+		return []reflect.Value{rv.Elem()}
+	case reflect.Slice:
+		out := make([]reflect.Value, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			out = append(out, rv.Index(i))
+		}
+		return out
+	case reflect.Map:
+		keys := rv.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return keys[i].String() < keys[j].String()
+		})
+		out := make([]reflect.Value, 0, len(keys))
+		for _, k := range keys {
+			out = append(out, rv.MapIndex(k))
+		}
+		return out
+	}
+	return []reflect.Value{rv}
+}
+
+func compareNumber(a, b reflect.Value) int {
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y := a.Int(), b.Int()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, y := a.Uint(), b.Uint()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	default:
+		x, y := a.Float(), b.Float()
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+	}
+	return 0
+}
+
+// checkConstraints checks the value of f against constraints,
+// it returns a *ConstraintError for the first violated constraint.
+// A zero value is not checked unless isSet is true,
+// use modifier `R` to require a value.
+func (f *_flag) checkConstraints(isSet bool) error {
+	if len(f.constraints) == 0 || (f.isZero() && !isSet) {
+		return nil
+	}
+	for _, c := range f.constraints {
+		if invalid, ok := c.check(f); !ok {
+			return &ConstraintError{
+				Name:       f.name,
+				IsArgument: f.nonflag,
+				Value:      invalid,
+				Constraint: c.tag,
+				Limit:      c.value,
+			}
+		}
+	}
+	return nil
+}
+
+func (f *_flag) formatConstraintsForHelp() string {
+	if len(f.constraints) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(f.constraints))
+	for _, c := range f.constraints {
+		parts = append(parts, fmt.Sprintf("%s: %s", c.tag, c.value))
+	}
+	return fmt.Sprintf("[%s]", strings.Join(parts, ", "))
+}
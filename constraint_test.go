@@ -0,0 +1,98 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_Constraints(t *testing.T) {
+	type cmdArgs struct {
+		Port    int             `cli:"-p, --port, The port" min:"1" max:"65535"`
+		Ratio   *float64        `cli:"--ratio" min:"0" max:"1"`
+		Timeout time.Duration   `cli:"--timeout" max:"1m"`
+		Name    string          `cli:"-n, --name" pattern:"^[a-z]+$" minlen:"2" maxlen:"8"`
+		Tags    []string        `cli:"--tags" maxlen:"2" pattern:"^t"`
+		Limits  map[string]uint `cli:"--limits" max:"10"`
+		File    string          `cli:"file" minlen:"3"`
+	}
+
+	resetDefaultApp()
+	args1 := &cmdArgs{}
+	_, err := Parse(args1, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"abc", "-p", "8080", "--ratio", "0.5", "--timeout", "30s",
+			"-n", "demo", "--tags", "t1", "--limits", "a=10"}))
+	assert.Nil(t, err)
+
+	// Zero values are not checked.
+	resetDefaultApp()
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{}))
+	assert.Nil(t, err)
+
+	cases := []struct {
+		args       []string
+		constraint string
+		want       string
+	}{
+		{[]string{"-p", "0"}, "min", `value "0" for flag '-port' is invalid, must be no less than 1`},
+		{[]string{"-p", "65536"}, "max", `value "65536" for flag '-port' is invalid, must be no greater than 65535`},
+		{[]string{"--ratio", "1.5"}, "max", `value "1.5" for flag '-ratio' is invalid, must be no greater than 1`},
+		{[]string{"--timeout", "2m"}, "max", `value "2m0s" for flag '-timeout' is invalid, must be no greater than 1m`},
+		{[]string{"-n", "a"}, "minlen", `value "a" for flag '-name' is invalid, length must be no less than 2`},
+		{[]string{"-n", "Demo"}, "pattern", `value "Demo" for flag '-name' is invalid, must match pattern ^[a-z]+$`},
+		{[]string{"--tags", "t1", "--tags", "x2"}, "pattern", `value "x2" for flag '-tags' is invalid, must match pattern ^t`},
+		{[]string{"--tags", "t1", "--tags", "t2", "--tags", "t3"}, "maxlen", `for flag '-tags' is invalid, length must be no greater than 2`},
+		{[]string{"--limits", "a=1", "--limits", "b=11"}, "max", `value "11" for flag '-limits' is invalid, must be no greater than 10`},
+		{[]string{"ab"}, "minlen", `value "ab" for argument 'file' is invalid, length must be no less than 3`},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		resetDefaultApp()
+		defaultApp.getFlagSet().SetOutput(&buf)
+		_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+			WithArgs(c.args))
+		var constraintErr *ConstraintError
+		if assert.True(t, errors.As(err, &constraintErr), "args: %v", c.args) {
+			assert.Equal(t, c.constraint, constraintErr.Constraint)
+			assert.Contains(t, err.Error(), c.want)
+		}
+	}
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[min: 1, max: 65535]\n")
+	assert.Contains(t, got, "[minlen: 2, maxlen: 8, pattern: ^[a-z]+$]\n")
+
+	for _, args := range []any{
+		&struct {
+			Name string `cli:"--name" min:"1"`
+		}{},
+		&struct {
+			Port int `cli:"--port" pattern:"^1"`
+		}{},
+		&struct {
+			Port int `cli:"--port" minlen:"1"`
+		}{},
+		&struct {
+			Name string `cli:"--name" pattern:"("`
+		}{},
+		&struct {
+			Port int `cli:"--port" max:"abc"`
+		}{},
+	} {
+		resetDefaultApp()
+		assert.Panics(t, func() {
+			Parse(args, WithArgs([]string{}))
+		})
+	}
+}
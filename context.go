@@ -70,3 +70,113 @@ func (ctx *Context) FlagSet() *flag.FlagSet {
 func (ctx *Context) PrintHelp() {
 	ctx.app.printUsage()
 }
+
+// ValueSource tells where the value of a flag or argument comes from.
+type ValueSource int
+
+const (
+	// SourceUnset tells that the value is not set, it is the zero value.
+	SourceUnset ValueSource = iota
+	// SourceDefaultTag tells that the value comes from the struct tag `default`.
+	SourceDefaultTag
+	// SourceWithDefaults tells that the value comes from the option WithDefaults.
+	SourceWithDefaults
+	// SourceConfigFile tells that the value comes from a config file.
+	SourceConfigFile
+	// SourceEnv tells that the value comes from an environment variable.
+	SourceEnv
+	// SourceCommandLine tells that the value comes from command line.
+	SourceCommandLine
+)
+
+func (s ValueSource) String() string {
+	switch s {
+	case SourceDefaultTag:
+		return "default tag"
+	case SourceWithDefaults:
+		return "WithDefaults"
+	case SourceConfigFile:
+		return "config file"
+	case SourceEnv:
+		return "env"
+	case SourceCommandLine:
+		return "command line"
+	}
+	return "unset"
+}
+
+// ValueOrigin describes where the value of a flag or argument comes from.
+type ValueOrigin struct {
+	Source ValueSource
+
+	// EnvName is the environment variable which provides the value,
+	// when Source is SourceEnv.
+	EnvName string
+
+	// ConfigFile is the config file which provides the value,
+	// when Source is SourceConfigFile.
+	ConfigFile string
+}
+
+func (o ValueOrigin) String() string {
+	switch o.Source {
+	case SourceEnv:
+		return "env " + o.EnvName
+	case SourceConfigFile:
+		return "config file " + o.ConfigFile
+	}
+	return o.Source.String()
+}
+
+// isExplicit tells whether the value is given by user, from command line,
+// environment variables or config file.
+func (o ValueOrigin) isExplicit() bool {
+	return o.Source == SourceCommandLine || o.Source == SourceEnv || o.Source == SourceConfigFile
+}
+
+// ValueOrigin returns where the value of a flag or argument comes from,
+// name can be the long name or short name of a flag, the name of an
+// argument, or the env name of an environment variable argument
+// (marked by modifier `E`).
+// It returns false if the name is not found.
+func (ctx *Context) ValueOrigin(name string) (ValueOrigin, bool) {
+	pCtx := ctx.app.getParsingContext()
+	if f := pCtx.flagMap[name]; f != nil {
+		return f.origin, true
+	}
+	for _, f := range pCtx.nonflags {
+		if f.name == name {
+			return f.origin, true
+		}
+	}
+	if f := pCtx.passthrough; f != nil && f.name == name {
+		return f.origin, true
+	}
+	for _, f := range pCtx.envVars {
+		if f.envNames[0] == name {
+			return f.origin, true
+		}
+	}
+	return ValueOrigin{}, false
+}
+
+// ValueOrigins returns where the values of all flags and arguments
+// come from, the map is keyed by long names of flags, names of arguments,
+// and env names of environment variable arguments.
+func (ctx *Context) ValueOrigins() map[string]ValueOrigin {
+	pCtx := ctx.app.getParsingContext()
+	out := make(map[string]ValueOrigin)
+	for _, f := range pCtx.flags {
+		out[f.name] = f.origin
+	}
+	for _, f := range pCtx.nonflags {
+		out[f.name] = f.origin
+	}
+	if f := pCtx.passthrough; f != nil {
+		out[f.name] = f.origin
+	}
+	for _, f := range pCtx.envVars {
+		out[f.envNames[0]] = f.origin
+	}
+	return out
+}
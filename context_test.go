@@ -0,0 +1,70 @@
+package mcli
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_ValueOrigin(t *testing.T) {
+	type cmdArgs struct {
+		Host    string `cli:"-H, --host" env:"TEST_MCLI_HOST, TEST_MCLI_ADDR"`
+		Port    int    `cli:"-p, --port" default:"8080"`
+		Level   string `cli:"--level" default:"info"`
+		Format  string `cli:"--format"`
+		Verbose bool   `cli:"-v, --verbose"`
+		Timeout int    `cli:"--timeout"`
+		Name    string `cli:"name"`
+		Token   string `cli:"#E, The token" env:"TEST_MCLI_TOKEN"`
+	}
+	file := writeTestConfigFile(t, "app.json", `{"format": "json"}`)
+
+	resetDefaultApp()
+	defaultApp.ConfigFile = file
+	os.Setenv("TEST_MCLI_ADDR", "example.com")
+	os.Setenv("TEST_MCLI_TOKEN", "secret")
+	args := &cmdArgs{}
+	_, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-v", "abc"}),
+		WithDefaults(map[string]any{"level": "debug"}))
+	assert.Nil(t, err)
+
+	ctx := newContext(defaultApp)
+	origin, ok := ctx.ValueOrigin("host")
+	assert.True(t, ok)
+	assert.Equal(t, ValueOrigin{Source: SourceEnv, EnvName: "TEST_MCLI_ADDR"}, origin)
+	assert.Equal(t, "env TEST_MCLI_ADDR", origin.String())
+
+	origin, ok = ctx.ValueOrigin("p")
+	assert.True(t, ok)
+	assert.Equal(t, SourceDefaultTag, origin.Source)
+
+	origin, _ = ctx.ValueOrigin("level")
+	assert.Equal(t, SourceWithDefaults, origin.Source)
+
+	origin, _ = ctx.ValueOrigin("format")
+	assert.Equal(t, ValueOrigin{Source: SourceConfigFile, ConfigFile: file}, origin)
+
+	origin, _ = ctx.ValueOrigin("verbose")
+	assert.Equal(t, SourceCommandLine, origin.Source)
+
+	origin, _ = ctx.ValueOrigin("timeout")
+	assert.Equal(t, SourceUnset, origin.Source)
+	assert.Equal(t, "unset", origin.String())
+
+	origin, _ = ctx.ValueOrigin("name")
+	assert.Equal(t, SourceCommandLine, origin.Source)
+
+	origin, _ = ctx.ValueOrigin("TEST_MCLI_TOKEN")
+	assert.Equal(t, ValueOrigin{Source: SourceEnv, EnvName: "TEST_MCLI_TOKEN"}, origin)
+
+	_, ok = ctx.ValueOrigin("not-exists")
+	assert.False(t, ok)
+
+	origins := ctx.ValueOrigins()
+	assert.Len(t, origins, 8)
+	assert.Equal(t, SourceCommandLine, origins["verbose"].Source)
+	assert.Equal(t, SourceDefaultTag, origins["port"].Source)
+}
@@ -26,9 +26,10 @@ func SetGlobalFlags(v any) {
 // Add adds a command.
 //
 // Param cmd must be type of one of the following:
-//   - `func()`, user should call `mcli.Parse` inside the function
-//   - `func(ctx *mcli.Context)`, user should call `ctx.Parse` inside the function
-//   - a Command created by NewCommand
+//   - `func()` or `func() error`, user should call `mcli.Parse` inside the function
+//   - `func(ctx *mcli.Context)` or `func(ctx *mcli.Context) error`,
+//     user should call `ctx.Parse` inside the function
+//   - a Command created by NewCommand or NewCommandE
 func Add(name string, cmd any, description string, opts ...CmdOpt) {
 	defaultApp.Add(name, cmd, description, opts...)
 }
@@ -104,6 +105,14 @@ func Run(args ...string) {
 	defaultApp.Run(args...)
 }
 
+// RunE is similar to Run, but it never exits the program.
+// Instead, it returns the error which occurs when searching for the command,
+// parsing flags and arguments, or returned by the command itself.
+// It returns nil when help is requested and printed.
+func RunE(args ...string) error {
+	return defaultApp.RunE(args...)
+}
+
 // Parse parses the command line for flags and arguments.
 // `args` must be a pointer to a struct, else it panics.
 //
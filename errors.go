@@ -0,0 +1,326 @@
+package mcli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// The following error types are reported by Parse and App.RunE,
+// user can use errors.As to check the concrete error and react
+// programmatically, e.g. when option `WithErrorHandling(flag.ContinueOnError)`
+// is used.
+
+// HelpRequestedError is reported when help is requested by "-h" or "--help",
+// it unwraps to flag.ErrHelp, thus errors.Is(err, flag.ErrHelp)
+// keeps working as with package "flag".
+type HelpRequestedError struct {
+	// Command is the name of the command which help is requested for,
+	// it is empty for the root command.
+	Command string
+}
+
+func (e *HelpRequestedError) Error() string {
+	return flag.ErrHelp.Error()
+}
+
+func (e *HelpRequestedError) Unwrap() error {
+	return flag.ErrHelp
+}
+
+// InvalidCommandError is reported when the requested command does not exist.
+type InvalidCommandError struct {
+	// GroupName is the name of the longest matched command group,
+	// it is empty if no group matches.
+	GroupName string
+
+	// Command is the full invalid command name.
+	Command string
+
+	// Suggestions holds the names of commands similar to Command.
+	Suggestions []string
+
+	// Ambiguous tells that Command is an abbreviation which matches
+	// more than one command, when Options.AllowCommandAbbreviation
+	// is enabled, the matched commands are in Suggestions.
+	Ambiguous bool
+}
+
+func (e *InvalidCommandError) Error() string {
+	cmdName := getProgramName()
+	if e.GroupName != "" {
+		cmdName += " " + e.GroupName
+	}
+	if e.Ambiguous {
+		return fmt.Sprintf("'%s' is an ambiguous command. See '%s -h' for help.", e.Command, cmdName)
+	}
+	return fmt.Sprintf("'%s' is not a valid command. See '%s -h' for help.", e.Command, cmdName)
+}
+
+// ExperimentalCommandError is reported when an experimental command
+// is invoked, but experimental commands are not enabled.
+type ExperimentalCommandError struct {
+	Command string
+
+	// Env is the environment variable to enable experimental commands,
+	// it is empty if Options.ExperimentalEnv is not set.
+	Env string
+}
+
+func (e *ExperimentalCommandError) Error() string {
+	msg := fmt.Sprintf("'%s' is an experimental command, enable it by flag '--%s'", e.Command, experimentalFlag)
+	if e.Env != "" {
+		msg += fmt.Sprintf(" or env %s=1", e.Env)
+	}
+	return msg
+}
+
+// PluginError is reported when an external plugin command fails,
+// see Options.EnablePlugins.
+type PluginError struct {
+	Plugin string
+	Path   string
+	Err    error
+}
+
+func (e *PluginError) Error() string {
+	return fmt.Sprintf("plugin '%s' failed: %v", e.Plugin, e.Err)
+}
+
+func (e *PluginError) Unwrap() error {
+	return e.Err
+}
+
+// UnexpectedArgsError is reported when there are more positional arguments
+// than the command accepts.
+type UnexpectedArgsError struct {
+	Args []string
+}
+
+func (e *UnexpectedArgsError) Error() string {
+	return fmt.Sprintf("got unexpected %s", formatErrorArguments(e.Args))
+}
+
+// MissingFlagError is reported when a required flag is not set.
+type MissingFlagError struct {
+	// Flag is the name of the flag, without leading dashes.
+	Flag string
+}
+
+func (e *MissingFlagError) Error() string {
+	return fmt.Sprintf("flag is required but not set: -%s", e.Flag)
+}
+
+// MissingArgumentError is reported when a required positional argument
+// is not given.
+type MissingArgumentError struct {
+	Argument string
+}
+
+func (e *MissingArgumentError) Error() string {
+	return fmt.Sprintf("argument is required but not given: %v", e.Argument)
+}
+
+// MissingEnvError is reported when a required environment variable,
+// which is defined with modifier `E`, is not set.
+type MissingEnvError struct {
+	EnvNames []string
+}
+
+func (e *MissingEnvError) Error() string {
+	return fmt.Sprintf("environment variable is required but not set: %v", strings.Join(e.EnvNames, ", "))
+}
+
+// InvalidEnumError is reported when the value of a flag or argument
+// is not one of the valid enum values.
+type InvalidEnumError struct {
+	// Name is the name of the flag or argument.
+	Name string
+
+	// IsArgument tells whether Name is a positional argument.
+	IsArgument bool
+
+	Value string
+	Enums []string
+}
+
+func (e *InvalidEnumError) Error() string {
+	return fmt.Sprintf("value for %s is invalid, must be one of: %s",
+		formatHelpName(e.Name, e.IsArgument), strings.Join(e.Enums, ", "))
+}
+
+// ConstraintError is reported when the value of a flag or argument
+// violates a constraint defined by struct tag, e.g. `min:"1"`.
+type ConstraintError struct {
+	// Name is the name of the flag or argument.
+	Name string
+
+	// IsArgument tells whether Name is a positional argument.
+	IsArgument bool
+
+	// Value is the invalid value, for a constraint on each element of
+	// a slice or map, it is the invalid element.
+	Value string
+
+	// Constraint is the name of the violated constraint,
+	// one of "min", "max", "minlen", "maxlen" and "pattern".
+	Constraint string
+
+	// Limit is the value of the constraint tag.
+	Limit string
+}
+
+func (e *ConstraintError) Error() string {
+	var desc string
+	switch e.Constraint {
+	case "min":
+		desc = "must be no less than " + e.Limit
+	case "max":
+		desc = "must be no greater than " + e.Limit
+	case "minlen":
+		desc = "length must be no less than " + e.Limit
+	case "maxlen":
+		desc = "length must be no greater than " + e.Limit
+	case "pattern":
+		desc = "must match pattern " + e.Limit
+	}
+	return fmt.Sprintf("value %q for %s is invalid, %s",
+		e.Value, formatHelpName(e.Name, e.IsArgument), desc)
+}
+
+// FlagConstraintError is reported when flags violate a constraint
+// declared by WithFlagConstraints.
+type FlagConstraintError struct {
+	// Constraint is the kind of the violated constraint,
+	// one of "exclusive", "one-required" and "required-together".
+	Constraint string
+
+	// Flags holds the long names of the flags in the group.
+	Flags []string
+
+	// SetFlags holds the long names of the flags which are set.
+	SetFlags []string
+}
+
+func (e *FlagConstraintError) Error() string {
+	switch e.Constraint {
+	case flagGroupExclusive:
+		return fmt.Sprintf("flags cannot be set together: %s", formatFlagNames(e.SetFlags))
+	case flagGroupOneRequired:
+		return fmt.Sprintf("one of flags is required but not set: %s", formatFlagNames(e.Flags))
+	default:
+		var unset []string
+		for _, name := range e.Flags {
+			if find(e.SetFlags, name) < 0 {
+				unset = append(unset, name)
+			}
+		}
+		return fmt.Sprintf("flags must be set together: %s, missing: %s",
+			formatFlagNames(e.Flags), formatFlagNames(unset))
+	}
+}
+
+// InvalidValueError is reported when a value from command line or
+// environment variable cannot be converted to the type of a flag or argument.
+type InvalidValueError struct {
+	// Name is the name of the flag or argument.
+	Name string
+
+	// IsArgument tells whether Name is a positional argument.
+	IsArgument bool
+
+	// Env is the name of environment variable where Value comes from,
+	// it is empty if Value comes from the command line.
+	Env string
+
+	// Config is the name of config file where Value comes from,
+	// it is empty if Value comes from the command line.
+	Config string
+
+	Value string
+	Err   error
+}
+
+func (e *InvalidValueError) Error() string {
+	helpName := formatHelpName(e.Name, e.IsArgument)
+	if e.Env != "" {
+		return fmt.Sprintf("invalid value %q for %s from env %s: %v", e.Value, helpName, e.Env, e.Err)
+	}
+	if e.Config != "" {
+		return fmt.Sprintf("invalid value %q for %s from config file %s: %v", e.Value, helpName, e.Config, e.Err)
+	}
+	return fmt.Sprintf("invalid value %q for %s: %v", e.Value, helpName, e.Err)
+}
+
+func (e *InvalidValueError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors holds multiple errors found when parsing flags and
+// arguments, it is reported when Options.ReportAllErrors is enabled and
+// more than one error is found.
+type ValidationErrors []error
+
+func (e ValidationErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Is reports whether any error in e matches target,
+// it makes errors.Is work with ValidationErrors.
+func (e ValidationErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first error in e that matches target, and if one is found,
+// sets target to that error value and returns true.
+// It makes errors.As work with ValidationErrors.
+func (e ValidationErrors) As(target any) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unwrap returns the errors, for Go 1.20 or later.
+func (e ValidationErrors) Unwrap() []error {
+	return e
+}
+
+func formatHelpName(name string, isArgument bool) string {
+	if isArgument {
+		return fmt.Sprintf("argument '%s'", name)
+	}
+	return fmt.Sprintf("flag '-%s'", name)
+}
+
+func formatErrorArguments(args []string) string {
+	if len(args) == 1 {
+		return fmt.Sprintf("argument: '%s'", args[0])
+	}
+	return fmt.Sprintf("arguments: '%s'", strings.Join(args, " "))
+}
+
+func newProgramingError(format string, args ...any) *programingError {
+	msg := fmt.Sprintf(format, args...)
+	return &programingError{msg: msg}
+}
+
+type programingError struct {
+	msg string
+}
+
+func (e *programingError) Error() string {
+	return e.msg
+}
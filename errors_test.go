@@ -0,0 +1,159 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorTypes(t *testing.T) {
+	parse := func(args any, opts ...ParseOpt) error {
+		resetDefaultApp()
+		defaultApp.getFlagSet().SetOutput(&bytes.Buffer{})
+		opts = append(opts, WithErrorHandling(flag.ContinueOnError))
+		_, err := Parse(args, opts...)
+		return err
+	}
+
+	t.Run("unexpected args", func(t *testing.T) {
+		var args struct {
+			V bool   `cli:"-v"`
+			A string `cli:"a"`
+		}
+		err := parse(&args, WithArgs([]string{"a1", "-v", "a2", "a3"}))
+		var target *UnexpectedArgsError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, []string{"a2", "a3"}, target.Args)
+	})
+
+	t.Run("missing flag", func(t *testing.T) {
+		var args struct {
+			Name string `cli:"#R, -n, --name"`
+		}
+		err := parse(&args, WithArgs([]string{}))
+		var target *MissingFlagError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "name", target.Flag)
+	})
+
+	t.Run("missing argument", func(t *testing.T) {
+		var args struct {
+			Text string `cli:"#R, text"`
+		}
+		err := parse(&args, WithArgs([]string{}))
+		var target *MissingArgumentError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "text", target.Argument)
+	})
+
+	t.Run("missing env", func(t *testing.T) {
+		var args struct {
+			Secret string `cli:"#ER, The secret" env:"SOME_SECRET"`
+		}
+		err := parse(&args, WithArgs([]string{}))
+		var target *MissingEnvError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, []string{"SOME_SECRET"}, target.EnvNames)
+	})
+
+	t.Run("invalid enum", func(t *testing.T) {
+		var args struct {
+			Action string `cli:"action"`
+		}
+		err := parse(&args, WithArgs([]string{"jump"}),
+			WithEnums(map[string][]string{"action": {"start", "stop"}}))
+		var target *InvalidEnumError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "action", target.Name)
+		assert.True(t, target.IsArgument)
+		assert.Equal(t, "jump", target.Value)
+		assert.Equal(t, []string{"start", "stop"}, target.Enums)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		var args struct {
+			Count int `cli:"count"`
+		}
+		err := parse(&args, WithArgs([]string{"abc"}))
+		var target *InvalidValueError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "count", target.Name)
+		assert.Equal(t, "abc", target.Value)
+		assert.Equal(t, "", target.Env)
+		assert.NotNil(t, errors.Unwrap(err))
+	})
+
+	t.Run("invalid flag value by short name", func(t *testing.T) {
+		var args struct {
+			Port int `cli:"-p, --port"`
+		}
+		err := parse(&args, WithArgs([]string{"-p", "x"}))
+		var target *InvalidValueError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "port", target.Name)
+		assert.Equal(t, "x", target.Value)
+		assert.Contains(t, err.Error(), `invalid value "x" for flag '-port'`)
+	})
+
+	t.Run("invalid env value", func(t *testing.T) {
+		var args struct {
+			Count int `cli:"-c, --count" env:"SOME_COUNT"`
+		}
+		resetDefaultApp()
+		os.Setenv("SOME_COUNT", "abc")
+		defer os.Unsetenv("SOME_COUNT")
+		var buf bytes.Buffer
+		defaultApp.getFlagSet().SetOutput(&buf)
+		_, err := Parse(&args, WithArgs([]string{}), WithErrorHandling(flag.ContinueOnError))
+		var target *InvalidValueError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "count", target.Name)
+		assert.Equal(t, "SOME_COUNT", target.Env)
+		assert.Contains(t, err.Error(), `invalid value "abc" for flag '-count' from env SOME_COUNT`)
+
+		// An invalid env value is reported like other invalid values,
+		// the error and usage are printed, and error handling applies.
+		got := buf.String()
+		assert.Contains(t, got, `invalid value "abc" for flag '-count' from env SOME_COUNT`)
+		assert.Contains(t, got, "Usage:")
+
+		resetDefaultApp()
+		os.Setenv("SOME_COUNT", "abc")
+		defaultApp.getFlagSet().SetOutput(&bytes.Buffer{})
+		assert.PanicsWithError(t, err.Error(), func() {
+			Parse(&args, WithArgs([]string{}), WithErrorHandling(flag.PanicOnError))
+		})
+	})
+
+	t.Run("help requested", func(t *testing.T) {
+		var err error
+		app := NewApp()
+		app.Add("group1 cmd1", func(ctx *Context) {
+			ctx.Parse(nil, WithErrorHandling(flag.ContinueOnError))
+			err = ctx.ArgsError()
+		}, "group1 cmd1")
+		app.getFlagSet().SetOutput(&bytes.Buffer{})
+		assert.Nil(t, app.RunE("group1", "cmd1", "-h"))
+		var target *HelpRequestedError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "group1 cmd1", target.Command)
+		assert.ErrorIs(t, err, flag.ErrHelp)
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		app := NewApp()
+		app.Add("group1 cmd1", dummyCmdWithContext, "group1 cmd1")
+		app.Add("group1 cmd2", dummyCmdWithContext, "group1 cmd2")
+		app.getFlagSet().SetOutput(&bytes.Buffer{})
+		err := app.RunE("group1", "cmd3")
+		var target *InvalidCommandError
+		assert.True(t, errors.As(err, &target))
+		assert.Equal(t, "group1", target.GroupName)
+		assert.Equal(t, "group1 cmd3", target.Command)
+		assert.Equal(t, []string{"group1 cmd1", "group1 cmd2"}, target.Suggestions)
+	})
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 
@@ -228,7 +229,7 @@ func Example_githubCliCommandBrowse() {
 		Location string `cli:"location, A browser location can be specified using arguments in the following format:\n- by number for issue or pull request, e.g. \"123\"; or\n- by path for opening folders and files, e.g. \"cmd/gh/main.go\""`
 	}{}
 	_, err := mcli.Parse(&args, mcli.WithErrorHandling(flag.ContinueOnError))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Printf("mcli.Parse error: %v", err)
 		fmt.Println()
 	}
@@ -259,7 +260,7 @@ func Example_githubCliCommandIssueClose() {
 		CommonIssueArgs
 	}
 	_, err := mcli.Parse(&args, mcli.WithErrorHandling(flag.ContinueOnError))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Printf("mcli.Parse error: %v", err)
 		fmt.Println()
 	}
@@ -299,7 +300,7 @@ func Example_githubCliCommandIssueComment() {
 		CommonIssueArgs
 	}
 	_, err := mcli.Parse(&args, mcli.WithErrorHandling(flag.ContinueOnError))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Printf("mcli.Parse error: %v", err)
 		fmt.Println()
 	}
@@ -354,7 +355,7 @@ func Example_githubCliCommandIssueCreate() {
 		CommonIssueArgs
 	}
 	_, err := mcli.Parse(&args, mcli.WithErrorHandling(flag.ContinueOnError))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Printf("mcli.Parse error: %v", err)
 		fmt.Println()
 	}
@@ -415,7 +416,7 @@ func Example_githubCliCommandIssueEdit() {
 
 	// Optionally, we can specify a custom ErrorHandling option.
 	_, err := mcli.Parse(&args, mcli.WithErrorHandling(flag.ContinueOnError))
-	if err != nil && err != flag.ErrHelp {
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
 		fmt.Printf("mcli.Parse error: %v", err)
 		fmt.Println()
 	}
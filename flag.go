@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"net/url"
 	"reflect"
 	"regexp"
 	"sort"
@@ -20,10 +21,17 @@ import (
 // Fow now the following modifiers are available:
 //
 //	D - marks a flag or argument as deprecated, "DEPRECATED" will be shown in help.
+//	    A warning is printed when the flag or argument is used.
 //	R - marks a flag or argument as required, "REQUIRED" will be shown in help.
 //	H - marks a flag as hidden, see below for more about hidden flags.
 //	E - marks an argument read from environment variables, but not command line,
 //	    environment variables will be shown in a separate section in help.
+//	P - marks an argument to receive the raw arguments after the terminator "--",
+//	    the argument must be type of []string.
+//	N - marks a boolean flag as negatable, a flag "--no-<name>" is added
+//	    automatically to set the flag to false.
+//	C - marks an integer flag as a counter, the value increases by one
+//	    every time the flag appears, e.g. "-v -v", "-vv".
 //
 // Hidden flags don't show in help, except that when a special flag
 // "--mcli-show-hidden" is provided.
@@ -36,6 +44,22 @@ import (
 // but don't want user to provide from command line (e.g. password or other secrets).
 // Using together with `R` also ensures that the env variable must exist.
 //
+// Modifier `P` is useful for wrapper commands, which pass arguments
+// verbatim to another program, e.g. `wrapper -v pod1 -- ls -l`.
+// Arguments after "--" are not parsed as flags or regular arguments.
+// At most one argument can be marked with `P`.
+//
+// Modifier `N` is useful for a boolean flag which defaults to true,
+// e.g. with `cli:"#N, --color" default:"true"`, the flag can be turned off
+// by "--no-color", and it is shown in help as "--[no-]color".
+//
+// Modifier `C` is useful for verbosity levels, e.g. with `cli:"#C, -v, --verbose"`,
+// "-v -v", "-vv" (when Options.AllowPosixSTMO is enabled) and
+// "--verbose --verbose" all set the flag to 2. An explicit value sets the
+// counter directly, e.g. "--verbose=3". A counter is shown in help as "--verbose...".
+// A value from env, config file or default value is replaced, instead of
+// increased, when the flag is given from command line.
+//
 // Some modifiers cannot be used together, else it panics, e.g.
 //
 //	H & R - a required flag must appear in help to tell user to set it
@@ -45,12 +69,18 @@ type Modifier byte
 
 func (m Modifier) apply(f *_flag) {
 	switch byte(m) {
+	case 'C':
+		f.counter = true
 	case 'D':
 		f.deprecated = true
 	case 'E':
 		f.isEnvVar = true
 	case 'H':
 		f.hidden = true
+	case 'N':
+		f.negatable = true
+	case 'P':
+		f.passthrough = true
 	case 'R':
 		f.required = true
 	}
@@ -75,6 +105,9 @@ type _flag struct {
 	defValue    string
 	envNames    []string
 	enums       []string
+	enumDescs   map[string]string
+	constraints []*constraint
+	groups      []*flagGroup
 	_tags
 	_value
 
@@ -85,12 +118,56 @@ type _flag struct {
 	hidden     bool
 	required   bool
 	nonflag    bool
+
+	passthrough bool
+	negatable   bool
+	counter     bool
+
+	// noEnv tells that the flag opts out the env name derived from
+	// Options.EnvPrefix, by tag `env:"-"`.
+	noEnv bool
+
+	// configKey is the key to load value of the flag from config file,
+	// it is empty if config file is not enabled.
+	configKey string
+
+	// origin tells where the value of the flag comes from.
+	origin ValueOrigin
+
+	// heading is the heading to group flags of a prefixed nested struct
+	// in help.
+	heading string
+
+	// deprecatedMsg is the message to print when a deprecated flag
+	// is used, by tag `deprecated`.
+	deprecatedMsg string
+
+	// renamedFrom are the old names of the flag, by tag `renamed`,
+	// they are accepted from command line but not shown in help.
+	renamedFrom []string
+
+	// timeLayout is the layout to parse time values, by tag `layout`.
+	timeLayout string
+
+	// listSep is the separator to split values of a slice or map,
+	// noSplit tells that values are not split, by tag `sep`.
+	listSep string
+	noSplit bool
+
+	// resetOnSet tells that the value of a slice or map flag is not
+	// from command line, it is replaced instead of appended on next Set.
+	resetOnSet bool
 }
 
 type _tags struct {
 	cliTag          string
 	defaultValueTag string
 	envTag          string
+	enumTag         string
+	layoutTag       string
+	sepTag          string
+	deprecatedTag   string
+	renamedTag      string
 }
 
 type _value struct {
@@ -115,6 +192,11 @@ func (f *_flag) Get() any {
 }
 
 func (f *_flag) String() string {
+	if f.timeLayout != "" {
+		if s, ok := formatTimeValue(f.rv, f.timeLayout); ok {
+			return s
+		}
+	}
 	return formatValue(f.rv)
 }
 
@@ -169,12 +251,44 @@ func formatValueOfAnyType(rv reflect.Value) string {
 		if rv.Len() == 0 {
 			return ""
 		}
+	case reflect.Struct:
+		if rv.Type() == urlTyp {
+			u := rv.Interface().(url.URL)
+			return u.String()
+		}
 	}
 	b, _ := json.Marshal(rv.Interface())
 	return string(b)
 }
 
+// Set sets a raw value from command line, a value of slice or map is
+// split by the separator specified by tag `sep`.
 func (f *_flag) Set(s string) error {
+	if f.listSep != "" && f.isCompositeType() {
+		for _, x := range splitBySep(s, f.listSep) {
+			if err := f.setElem(x); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return f.setElem(s)
+}
+
+// setElem sets an element of a slice or map, which is already split,
+// or the value of other types.
+func (f *_flag) setElem(s string) error {
+	if f.resetOnSet {
+		f.resetOnSet = false
+		f.rv.Set(reflect.Zero(f.rv.Type()))
+	}
+	return f.setValue(s)
+}
+
+func (f *_flag) setValue(s string) error {
+	if f.timeLayout != "" {
+		return applyTimeValue(f.rv, s, f.timeLayout)
+	}
 	return applyValue(f.rv, s)
 }
 
@@ -182,6 +296,9 @@ func applyValue(rv reflect.Value, s string) error {
 	if s == "" {
 		return nil
 	}
+	if isURLType(rv.Type()) {
+		return applyURLValue(rv, s)
+	}
 	if rv.Kind() == reflect.Ptr && rv.IsNil() {
 		rv.Set(reflect.New(rv.Type().Elem()))
 	}
@@ -292,8 +409,14 @@ func (f *_flag) isBoolean() bool {
 	return f.rv.Kind() == reflect.Bool
 }
 
+// isBoolFlag tells whether the flag does not take a value from
+// command line, i.e. a boolean flag or a counter.
+func (f *_flag) isBoolFlag() bool {
+	return f.isBoolean() || f.isBooleanPtr() || f.counter
+}
+
 func (f *_flag) isSlice() bool {
-	return f.rv.Kind() == reflect.Slice
+	return f.rv.Kind() == reflect.Slice && !isScalarTextValue(f.rv)
 }
 
 func (f *_flag) isMap() bool {
@@ -301,8 +424,7 @@ func (f *_flag) isMap() bool {
 }
 
 func (f *_flag) isCompositeType() bool {
-	kind := f.rv.Kind()
-	return kind == reflect.Slice || kind == reflect.Map
+	return f.isSlice() || f.isMap()
 }
 
 func (f *_flag) isString() bool {
@@ -316,6 +438,9 @@ func (f *_flag) isStringPtr() bool {
 
 func (f *_flag) isZero() bool {
 	typ := f.rv.Type()
+	if f.timeLayout != "" && typ.Comparable() {
+		return f.rv.IsZero()
+	}
 	if isFlagValueImpl(f.rv) {
 		zero := zeroFlagValueStr(f.rv)
 		return f.String() == zero
@@ -332,17 +457,49 @@ func (f *_flag) isZero() bool {
 	return f.rv.Len() == 0
 }
 
-func (f *_flag) helpName() string {
-	if f.nonflag {
-		return fmt.Sprintf("argument '%s'", f.name)
+// negatedName returns the name of the negated flag "--no-<name>"
+// of a negatable boolean flag.
+func (f *_flag) negatedName() string {
+	return "no-" + f.name
+}
+
+// isAccumulative tells whether values of f accumulate when it is given
+// multiple times from command line, i.e. a slice, a map or a counter.
+func (f *_flag) isAccumulative() bool {
+	return f.isCompositeType() || f.counter
+}
+
+// hasName tells whether name is the long name, short name, or the
+// negated name of the flag.
+func (f *_flag) hasName(name string) bool {
+	return name == f.name || (f.short != "" && name == f.short) ||
+		(f.negatable && name == f.negatedName())
+}
+
+// setEnvPrefix derives the env name from prefix and the flag name,
+// if the flag does not specify env names by tag, e.g. "MYAPP_DB_HOST"
+// for flag "--db-host" with prefix "MYAPP".
+func (f *_flag) setEnvPrefix(prefix string) {
+	if prefix == "" || f.noEnv || len(f.envNames) > 0 {
+		return
 	}
-	return fmt.Sprintf("flag '-%s'", f.name)
+	name := strings.ToUpper(envNameReplacer.Replace(f.name))
+	f.envNames = []string{strings.TrimRight(prefix, "_") + "_" + name}
+}
+
+var envNameReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+func (f *_flag) helpName() string {
+	return formatHelpName(f.name, f.nonflag)
 }
 
 func (f *_flag) usageName() string {
-	if f.isBoolean() || f.isBooleanPtr() {
+	if f.isBoolFlag() {
 		return ""
 	}
+	if name := builtinTypeNames[derefType(f.rv.Type())]; name != "" {
+		return name
+	}
 	if isFlagValueImpl(f.rv) {
 		return "value"
 	}
@@ -350,6 +507,9 @@ func (f *_flag) usageName() string {
 }
 
 func usageName(typ reflect.Type) string {
+	if name := builtinTypeNames[derefType(typ)]; name != "" {
+		return name
+	}
 	if isSupportedBasicTypePtr(typ) {
 		return usageName(typ.Elem())
 	}
@@ -396,17 +556,26 @@ func (f *_flag) getUsage(hasShortFlag bool) usageItem {
 	}
 	var prefix, description string
 	var appendixes []string
-	if f.nonflag {
+	longName := f.name
+	if f.negatable {
+		longName = "[no-]" + f.name
+	}
+	if f.counter {
+		longName += "..."
+	}
+	if f.passthrough {
+		prefix += "  -- " + f.name + "..."
+	} else if f.nonflag {
 		prefix += "  " + f.name
 	} else if f.short != "" && f.name != "" {
-		prefix += fmt.Sprintf("  -%s, --%s", f.short, f.name)
+		prefix += fmt.Sprintf("  -%s, --%s", f.short, longName)
 	} else if len(f.name) == 1 || !hasShortFlag {
-		prefix += fmt.Sprintf("  -%s", f.name)
+		prefix += fmt.Sprintf("  -%s", longName)
 	} else {
-		prefix += fmt.Sprintf("      --%s", f.name)
+		prefix += fmt.Sprintf("      --%s", longName)
 	}
 	name, description := unquoteUsage(f)
-	if name != "" {
+	if name != "" && !f.passthrough {
 		prefix += " " + name
 	}
 	var modifiers []string
@@ -422,6 +591,9 @@ func (f *_flag) getUsage(hasShortFlag bool) usageItem {
 	if len(modifiers) > 0 {
 		prefix += fmt.Sprintf(" [%s]", strings.Join(modifiers, ", "))
 	}
+	if f.deprecatedMsg != "" {
+		appendixes = append(appendixes, fmt.Sprintf("[deprecated: %s]", f.deprecatedMsg))
+	}
 	if dftStr := f.formatDefaultValueForHelp(); dftStr != "" {
 		appendixes = append(appendixes, dftStr)
 	}
@@ -429,9 +601,16 @@ func (f *_flag) getUsage(hasShortFlag bool) usageItem {
 		envStr := fmt.Sprintf(`[env: %s]`, strings.Join(f.envNames, ", "))
 		appendixes = append(appendixes, envStr)
 	}
-	if len(f.enums) > 0 {
-		enumStr := fmt.Sprintf(`[valid: %s]`, strings.Join(f.enums, ", "))
-		appendixes = append(appendixes, enumStr)
+	if f.configKey != "" {
+		configStr := fmt.Sprintf(`[config: %s]`, f.configKey)
+		appendixes = append(appendixes, configStr)
+	}
+	appendixes = append(appendixes, f.formatEnumsForHelp()...)
+	if constraintStr := f.formatConstraintsForHelp(); constraintStr != "" {
+		appendixes = append(appendixes, constraintStr)
+	}
+	for _, g := range f.groups {
+		appendixes = append(appendixes, g.formatForHelp(f))
 	}
 	return usageItem{
 		prefix:      prefix,
@@ -534,24 +713,28 @@ func (f *_flag) validate() error {
 	if f.deprecated && f.required {
 		return newProgramingError("modifiers D & R shall not be used together, %s", f.helpName())
 	}
-	if !isSupportedType(f.rv) {
-		return newProgramingError("unsupported value type %v for %s", f.rv.Type(), f.helpName())
+	if len(f.renamedFrom) > 0 && (f.nonflag || f.isEnvVar) {
+		return newProgramingError("tag renamed can only be used for a flag, %s", f.helpName())
 	}
-	if f.defaultValueTag != "" && !isFlagValueImpl(f.rv) && !isTextValueImpl(f.rv) {
-		if f.isSlice() {
-			return newProgramingError("default value is unsupported for slice type, %s", f.helpName())
-		}
-		if f.isMap() {
-			return newProgramingError("default value is unsupported for map type, %s", f.helpName())
-		}
+	if f.negatable && (f.nonflag || !(f.isBoolean() || f.isBooleanPtr())) {
+		return newProgramingError("modifier N can only be used for a boolean flag, %s", f.helpName())
+	}
+	if f.counter && (f.nonflag || f.isEnvVar || !isCounterType(f.rv.Type())) {
+		return newProgramingError("modifier C can only be used for an integer flag, %s", f.helpName())
 	}
-	if f.envTag != "" {
-		if f.isSlice() {
-			return newProgramingError("env is unsupported for slice type, %s", f.helpName())
+	if f.passthrough {
+		if f.isEnvVar || !f.nonflag {
+			return newProgramingError("modifier P can only be used for an argument, %s", f.helpName())
 		}
-		if f.isMap() {
-			return newProgramingError("env is unsupported for map type, %s", f.helpName())
+		if !f.isSlice() || f.rv.Type().Elem().Kind() != reflect.String {
+			return newProgramingError("modifier P requires type []string, %s", f.helpName())
 		}
+		if f.envTag != "" || f.defaultValueTag != "" {
+			return newProgramingError("env and default value are unsupported for modifier P, %s", f.helpName())
+		}
+	}
+	if !isSupportedType(f.rv) {
+		return newProgramingError("unsupported value type %v for %s", f.rv.Type(), f.helpName())
 	}
 	if f.isEnvVar {
 		if len(f.envNames) == 0 {
@@ -566,13 +749,20 @@ func (f *_flag) validate() error {
 }
 
 func parseFlags(isGlobal bool, fs *flag.FlagSet, rv reflect.Value, flagMap map[string]*_flag, opts *parseOptions) (
-	flags, nonflags, envVars []*_flag, err error,
+	p *flagParser, err error,
 ) {
-	p := &flagParser{
+	p = &flagParser{
 		fs:      fs,
 		flagMap: flagMap,
 		opts:    opts,
 	}
+	if err = p.parseStruct(isGlobal, rv); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *flagParser) parseStruct(isGlobal bool, rv reflect.Value) (err error) {
 	rt := rv.Type()
 
 	for i := 0; i < rt.NumField(); i++ {
@@ -582,24 +772,35 @@ func parseFlags(isGlobal bool, fs *flag.FlagSet, rv reflect.Value, flagMap map[s
 		if cliTag == "" {
 			cliTag = strings.TrimSpace(ft.Tag.Get("mcli"))
 		}
-		defaultValue := strings.TrimSpace(ft.Tag.Get("default"))
-		envTag := strings.TrimSpace(ft.Tag.Get("env"))
+		tags := _tags{
+			cliTag:          cliTag,
+			defaultValueTag: strings.TrimSpace(ft.Tag.Get("default")),
+			envTag:          strings.TrimSpace(ft.Tag.Get("env")),
+			enumTag:         strings.TrimSpace(ft.Tag.Get("enum")),
+			layoutTag:       strings.TrimSpace(ft.Tag.Get("layout")),
+			sepTag:          ft.Tag.Get("sep"),
+			deprecatedTag:   strings.TrimSpace(ft.Tag.Get("deprecated")),
+			renamedTag:      strings.TrimSpace(ft.Tag.Get("renamed")),
+		}
 
 		isGlobalFlag := isGlobal
-		if ft.Name == "GlobalFlags" && rt == reflect.TypeOf(withGlobalFlagArgs{}) {
+		if (ft.Name == "GlobalFlags" || ft.Name == "ConfigFlags") && rt == reflect.TypeOf(withGlobalFlagArgs{}) {
 			isGlobalFlag = true
 		}
 
-		err = p.parseField(ft, fv, isGlobalFlag, cliTag, defaultValue, envTag)
+		err = p.parseField(ft, fv, isGlobalFlag, tags)
 		if err != nil {
-			return nil, nil, nil, err
+			return err
 		}
 	}
 	if err = p.validateNonflags(); err != nil {
-		return nil, nil, nil, err
+		return err
 	}
 	p.sortFlags()
-	return p.flags, p.nonflags, p.envVars, nil
+	if v := getValidator(rv); v != nil {
+		p.validators = append(p.validators, v)
+	}
+	return nil
 }
 
 type flagParser struct {
@@ -610,6 +811,55 @@ type flagParser struct {
 	flags    []*_flag
 	nonflags []*_flag
 	envVars  []*_flag
+
+	passthrough *_flag
+	validators  []any
+
+	// namePrefix and envPrefix are prepended to names of flags and
+	// env names of a nested struct, by tags `prefix` and `envprefix`,
+	// the flags are grouped under heading in help.
+	namePrefix string
+	envPrefix  string
+	heading    string
+	headings   []string
+}
+
+// newSubParser returns a parser to parse the nested struct field ft.
+func (p *flagParser) newSubParser(ft reflect.StructField) *flagParser {
+	sub := &flagParser{
+		fs:         p.fs,
+		flagMap:    p.flagMap,
+		opts:       p.opts,
+		namePrefix: p.namePrefix,
+		envPrefix:  p.envPrefix,
+		heading:    p.heading,
+	}
+	prefix := strings.TrimSpace(ft.Tag.Get("prefix"))
+	if prefix == "" {
+		return sub
+	}
+	envPrefix, ok := ft.Tag.Lookup("envprefix")
+	if !ok {
+		envPrefix = strings.ToUpper(envNameReplacer.Replace(prefix))
+	}
+	heading := strings.TrimSpace(ft.Tag.Get("heading"))
+	if heading == "" {
+		heading = ft.Name + " Flags"
+	}
+	sub.namePrefix += prefix
+	sub.envPrefix += strings.TrimSpace(envPrefix)
+	sub.heading = heading
+	sub.headings = []string{heading}
+	return sub
+}
+
+func (p *flagParser) setPassthrough(f *_flag) error {
+	if p.passthrough != nil {
+		return newProgramingError("modifier P can be used for only one argument, got %s and %s",
+			p.passthrough.helpName(), f.helpName())
+	}
+	p.passthrough = f
+	return nil
 }
 
 func (p *flagParser) appendFlag(f *_flag) {
@@ -619,31 +869,119 @@ func (p *flagParser) appendFlag(f *_flag) {
 	if f.short != "" {
 		p.flagMap[f.short] = f
 	}
+	for _, name := range f.renamedFrom {
+		p.flagMap[name] = f
+	}
 	p.flags = append(p.flags, f)
 }
 
-func (p *flagParser) addToFlagSet(f *_flag, fv reflect.Value) {
+func (p *flagParser) addToFlagSet(f *_flag) {
 	fs := p.fs
-	if f.isBoolean() {
-		ptr := fv.Addr().Interface().(*bool)
-		fs.BoolVar(ptr, f.name, f.rv.Bool(), f.description)
-		if f.short != "" {
-			fs.BoolVar(ptr, f.short, f.rv.Bool(), f.description)
-		}
-		return
+	var value flag.Value = f
+	if f.counter {
+		value = &counterValue{rv: f.rv, f: f}
 	}
-	if f.isBooleanPtr() {
-		ptr := new(bool)
-		fs.BoolVar(ptr, f.name, false, f.description)
-		if f.short != "" {
-			fs.BoolVar(ptr, f.short, false, f.description)
+	v := &flagValue{Value: value, f: f, isBool: f.isBoolFlag()}
+	v.names = append(v.names, f.name)
+	if f.short != "" {
+		v.names = append(v.names, f.short)
+	}
+	for _, name := range v.names {
+		fs.Var(v, name, f.description)
+	}
+	if f.negatable {
+		negated := &flagValue{
+			Value:  &negatedBool{f},
+			f:      f,
+			isBool: true,
+			names:  append([]string{f.negatedName()}, v.names...),
 		}
-		return
+		fs.Var(negated, f.negatedName(), f.description)
+	}
+	for _, name := range f.renamedFrom {
+		renamed := &flagValue{
+			Value:       value,
+			f:           f,
+			isBool:      v.isBool,
+			names:       append([]string{name}, v.names...),
+			renamedFrom: name,
+		}
+		fs.Var(renamed, name, f.description)
 	}
-	fs.Var(f, f.name, f.description)
-	if f.short != "" {
-		fs.Var(f, f.short, f.description)
+}
+
+// negatedBool implements flag.Value for the negated flag "--no-<name>"
+// of a negatable boolean flag, it shares the value with the boolean flag.
+type negatedBool struct {
+	f *_flag
+}
+
+func (b *negatedBool) Get() any { return !b.f.Get().(bool) }
+
+func (b *negatedBool) IsBoolFlag() bool { return true }
+
+func (b *negatedBool) String() string {
+	if b.f == nil {
+		return "false"
+	}
+	return strconv.FormatBool(!b.f.Get().(bool))
+}
+
+func (b *negatedBool) Set(s string) error {
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	return b.f.Set(strconv.FormatBool(!v))
+}
+
+func isCounterType(typ reflect.Type) bool {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typ != reflect.TypeOf(time.Duration(0))
+	}
+	return false
+}
+
+// counterValue implements flag.Value for a counter flag,
+// the value increases by one every time the flag appears.
+// A value from env, config file or default value is replaced,
+// instead of increased, when the flag appears first time.
+type counterValue struct {
+	rv reflect.Value
+	f  *_flag
+}
+
+func (c *counterValue) Get() any { return c.rv.Interface() }
+
+func (c *counterValue) IsBoolFlag() bool { return true }
+
+func (c *counterValue) String() string {
+	if !c.rv.IsValid() {
+		return "0"
+	}
+	return formatValue(c.rv)
+}
+
+func (c *counterValue) Set(s string) error {
+	if c.f != nil && c.f.resetOnSet {
+		c.f.resetOnSet = false
+		c.rv.Set(reflect.Zero(c.rv.Type()))
+	}
+	switch s {
+	case "true":
+		switch c.rv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			c.rv.SetInt(c.rv.Int() + 1)
+		default:
+			c.rv.SetUint(c.rv.Uint() + 1)
+		}
+		return nil
+	case "false":
+		return applyIntegerValue(c.rv, "0")
 	}
+	return applyIntegerValue(c.rv, s)
 }
 
 func (p *flagParser) tidyFieldValue(ft reflect.StructField, fv reflect.Value, cliTag string) (reflect.Value, bool) {
@@ -666,64 +1004,126 @@ func (p *flagParser) tidyFieldValue(ft reflect.StructField, fv reflect.Value, cl
 func (p *flagParser) parseField(
 	ft reflect.StructField, fv reflect.Value,
 	isGlobalFlag bool,
-	cliTag, defaultValue, envTag string,
+	tags _tags,
 ) error {
-	fv, ok := p.tidyFieldValue(ft, fv, cliTag)
+	fv, ok := p.tidyFieldValue(ft, fv, tags.cliTag)
 	if !ok {
 		return nil
 	}
 
 	// Got a struct field, parse it recursively.
 	if fv.Kind() == reflect.Struct && !isFlagValueImpl(fv) && !isTextValueImpl(fv) {
-		subFlags, subNonflags, subEnvVars, subErr := parseFlags(isGlobalFlag, p.fs, fv, p.flagMap, p.opts)
-		if subErr != nil {
+		sub := p.newSubParser(ft)
+		if subErr := sub.parseStruct(isGlobalFlag, fv); subErr != nil {
 			return subErr
 		}
-		for _, f := range subFlags {
+		for _, f := range sub.flags {
 			p.appendFlag(f)
 		}
-		p.nonflags = append(p.nonflags, subNonflags...)
-		p.envVars = append(p.envVars, subEnvVars...)
+		p.nonflags = append(p.nonflags, sub.nonflags...)
+		p.envVars = append(p.envVars, sub.envVars...)
+		p.validators = append(p.validators, sub.validators...)
+		for _, h := range sub.headings {
+			if !contains(p.headings, h) {
+				p.headings = append(p.headings, h)
+			}
+		}
+		if sub.passthrough != nil {
+			return p.setPassthrough(sub.passthrough)
+		}
 		return nil
 	}
-	if cliTag == "" {
+	if tags.cliTag == "" {
 		return nil
 	}
 
 	// Parse the flag.
 	var f *_flag
-	f, err := p.parseFlag(isGlobalFlag, cliTag, defaultValue, envTag, fv)
+	f, err := p.parseFlag(isGlobalFlag, tags, fv)
 	if err != nil {
 		return err
 	}
 	if f == nil || f.name == "" {
 		return nil
 	}
+	if err = parseConstraints(f, ft.Tag); err != nil {
+		return err
+	}
 	if f.isEnvVar {
 		p.envVars = append(p.envVars, f)
 		return nil
 	}
+	if f.passthrough {
+		return p.setPassthrough(f)
+	}
 	if f.nonflag {
 		p.nonflags = append(p.nonflags, f)
 		return nil
 	}
 
+	if err = p.checkDuplicateNames(f); err != nil {
+		return err
+	}
 	p.appendFlag(f)
-	p.addToFlagSet(f, fv)
+	p.addToFlagSet(f)
+	return nil
+}
+
+// checkDuplicateNames reports a programming error if any name of f
+// is already used by another flag, including the global flags.
+func (p *flagParser) checkDuplicateNames(f *_flag) error {
+	names := append([]string{f.name, f.short}, f.renamedFrom...)
+	for _, name := range names {
+		x := p.flagMap[name]
+		if name == "" || x == nil {
+			continue
+		}
+		if x.isGlobal && !f.isGlobal {
+			if name == configFlagName {
+				return newProgramingError("flag '-%s' conflicts with the global flag '--%s', "+
+					"which may be added by Options.EnableConfigFlag", name, name)
+			}
+			return newProgramingError("flag '-%s' conflicts with a global flag", name)
+		}
+		return newProgramingError("flag '-%s' is defined more than once", name)
+	}
 	return nil
 }
 
 var spaceRE = regexp.MustCompile(`\s+`)
 
-func (p *flagParser) parseFlag(isGlobal bool, cliTag, defaultValue, envTag string, rv reflect.Value) (*_flag, error) {
+// applyPrefix namespaces a flag of a nested struct which has tag `prefix`,
+// the short name is dropped since it may conflict with other flags.
+func (p *flagParser) applyPrefix(f *_flag) {
+	if p.envPrefix != "" {
+		for i, name := range f.envNames {
+			f.envNames[i] = p.envPrefix + name
+		}
+	}
+	if p.namePrefix == "" || f.nonflag || f.isEnvVar {
+		return
+	}
+	f.name = p.namePrefix + f.name
+	f.short = ""
+	for i, name := range f.renamedFrom {
+		f.renamedFrom[i] = p.namePrefix + name
+	}
+	f.heading = p.heading
+}
+
+func (p *flagParser) parseFlag(isGlobal bool, tags _tags, rv reflect.Value) (*_flag, error) {
+	cliTag, defaultValue, envTag, enumTag := tags.cliTag, tags.defaultValueTag, tags.envTag, tags.enumTag
+
+	// `env:"-"` opts out the env name derived from Options.EnvPrefix.
+	noEnv := envTag == "-"
+	if noEnv {
+		tags.envTag, envTag = "", ""
+	}
 	f := &_flag{
-		_tags: _tags{
-			cliTag:          cliTag,
-			defaultValueTag: defaultValue,
-			envTag:          envTag,
-		},
+		_tags:    tags,
 		_value:   _value{rv},
 		isGlobal: isGlobal,
+		noEnv:    noEnv,
 	}
 
 	parseCliTag(f, cliTag)
@@ -737,18 +1137,38 @@ func (p *flagParser) parseFlag(isGlobal bool, cliTag, defaultValue, envTag strin
 	if envTag != "" {
 		f.envNames = splitByComma(envTag)
 	}
+	if tags.deprecatedTag != "" {
+		f.deprecated = true
+		f.deprecatedMsg = tags.deprecatedTag
+	}
+	for _, name := range splitByComma(tags.renamedTag) {
+		f.renamedFrom = append(f.renamedFrom, strings.TrimLeft(name, "-"))
+	}
+	p.applyPrefix(f)
 	if err := f.validate(); err != nil {
 		return nil, err
 	}
+	if err := f.parseLayoutTag(tags.layoutTag); err != nil {
+		return nil, err
+	}
+	if err := f.parseSepTag(tags.sepTag); err != nil {
+		return nil, err
+	}
 
-	// Apply enums from WithEnums option if provided
+	// Apply enums from struct tag, WithEnums option overrides it
+	if enumTag != "" {
+		f.parseEnumTag(enumTag)
+	}
 	if p.opts != nil && p.opts.enums != nil {
 		// Try long name first, then short name
 		enums := p.opts.enums[f.name]
 		if enums == nil && f.short != "" {
 			enums = p.opts.enums[f.short]
 		}
-		f.enums = enums
+		if enums != nil {
+			f.enums = enums
+			f.enumDescs = nil
+		}
 	}
 
 	// Apply WithDefaults option if provided
@@ -764,24 +1184,34 @@ func (p *flagParser) parseFlag(isGlobal bool, cliTag, defaultValue, envTag strin
 			}
 			f.defValue = formatValue(f.rv)
 			f.hasDefault = !f.isZero()
+			if f.hasDefault {
+				f.origin = ValueOrigin{Source: SourceWithDefaults}
+			}
 		}
 	}
 
 	// Apply struct tag default only if WithDefaults didn't override it
 	if !f.hasDefault && defaultValue != "" {
-		err := f.Set(defaultValue)
+		err := f.setDefaultValue(defaultValue)
 		if err != nil {
 			return nil, newProgramingError("invalid default value %q for %s: %v", defaultValue, f.helpName(), err)
 		}
 		f.defValue = defaultValue
 		f.hasDefault = !f.isZero()
+		if f.hasDefault {
+			f.origin = ValueOrigin{Source: SourceDefaultTag}
+		}
+	}
+
+	// Default values of slice, map and counter are replaced, instead of
+	// accumulated, when the flag or argument is given from command line.
+	if f.hasDefault && f.isAccumulative() {
+		f.resetOnSet = true
 	}
 
 	// Validate default value against enums if provided
 	if f.hasDefault && len(f.enums) > 0 {
-		val := f.String()
-		valid := find(f.enums, val) >= 0
-		if !valid {
+		if _, valid := f.checkEnums(); !valid {
 			return nil, newProgramingError("default value %q for %s is invalid, must be one of: %s", f.defValue, f.helpName(), strings.Join(f.enums, ", "))
 		}
 	}
@@ -789,6 +1219,107 @@ func (p *flagParser) parseFlag(isGlobal bool, cliTag, defaultValue, envTag strin
 	return f, nil
 }
 
+// parseEnumTag parses valid enum values from struct tag,
+// an enum value can optionally have a description separated by colon,
+// e.g. `enum:"json,yaml"`, `enum:"json:JSON output,yaml:YAML output"`.
+func (f *_flag) parseEnumTag(tag string) {
+	for _, x := range splitByComma(tag) {
+		value, desc, _ := strings.Cut(x, ":")
+		value, desc = strings.TrimSpace(value), strings.TrimSpace(desc)
+		f.enums = append(f.enums, value)
+		if desc != "" {
+			if f.enumDescs == nil {
+				f.enumDescs = make(map[string]string)
+			}
+			f.enumDescs[value] = desc
+		}
+	}
+}
+
+// checkEnums checks the value of f against the valid enum values,
+// each element of a slice is checked separately.
+// It returns the invalid value if f is not valid.
+func (f *_flag) checkEnums() (invalid string, ok bool) {
+	if f.rv.Kind() != reflect.Slice || isFlagValueImpl(f.rv) || isTextValueImpl(f.rv) {
+		val := f.String()
+		return val, find(f.enums, val) >= 0
+	}
+	for i := 0; i < f.rv.Len(); i++ {
+		val := formatValue(f.rv.Index(i))
+		if find(f.enums, val) < 0 {
+			return val, false
+		}
+	}
+	return "", true
+}
+
+func (f *_flag) formatEnumsForHelp() []string {
+	if len(f.enums) == 0 {
+		return nil
+	}
+	out := []string{fmt.Sprintf(`[valid: %s]`, strings.Join(f.enums, ", "))}
+	if len(f.enumDescs) == 0 {
+		return out
+	}
+	width := 0
+	for _, x := range f.enums {
+		if f.enumDescs[x] != "" && len(x) > width {
+			width = len(x)
+		}
+	}
+	for _, x := range f.enums {
+		if desc := f.enumDescs[x]; desc != "" {
+			out = append(out, fmt.Sprintf("  %-*s  %s", width, x, desc))
+		}
+	}
+	return out
+}
+
+// setDefaultValue sets the default value from struct tag,
+// the default value of a slice or map is split by comma,
+// e.g. "a,b" for []string, "k1=v1,k2=v2" for map[string]string.
+func (f *_flag) setDefaultValue(value string) error {
+	if !f.isCompositeType() || isFlagValueImpl(f.rv) || isTextValueImpl(f.rv) {
+		return f.Set(value)
+	}
+	for _, x := range f.splitListValue(value, ",") {
+		if err := f.setElem(x); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSepTag parses the tag `sep` of a slice or map, which specifies
+// the separator to split values from command line, env and default value,
+// `sep:"-"` disables splitting, only repeated flags append values.
+func (f *_flag) parseSepTag(tag string) error {
+	if tag == "" {
+		return nil
+	}
+	if !f.isCompositeType() {
+		return newProgramingError("sep is only supported for slice and map, %s", f.helpName())
+	}
+	if tag == "-" {
+		f.noSplit = true
+	} else {
+		f.listSep = tag
+	}
+	return nil
+}
+
+// splitListValue splits value of a slice or map by the separator
+// specified by tag `sep`, or sep if the tag is not given.
+func (f *_flag) splitListValue(value, sep string) []string {
+	if f.noSplit {
+		return []string{value}
+	}
+	if f.listSep != "" {
+		sep = f.listSep
+	}
+	return splitBySep(value, sep)
+}
+
 // applyDefaultValue applies a default value from WithDefaults to the flag's reflect value.
 func applyDefaultValue(rv reflect.Value, defaultValue any) error {
 	defVal := reflect.ValueOf(defaultValue)
@@ -909,12 +1440,15 @@ func isSupportedType(rv reflect.Value) bool {
 	if isSupportedBasicType(rv.Kind()) {
 		return true
 	}
-	if rv.Kind() == reflect.Slice && isSupportedBasicType(rv.Type().Elem().Kind()) {
+	if isURLType(rv.Type()) {
+		return true
+	}
+	if rv.Kind() == reflect.Slice && isSupportedElemType(rv.Type().Elem()) {
 		return true
 	}
 	if rv.Kind() == reflect.Map &&
 		rv.Type().Key().Kind() == reflect.String &&
-		isSupportedBasicType(rv.Type().Elem().Kind()) {
+		isSupportedElemType(rv.Type().Elem()) {
 		return true
 	}
 	return false
@@ -968,8 +1502,12 @@ func isSupportedBasicType(kind reflect.Kind) bool {
 }
 
 func splitByComma(value string) []string {
+	return splitBySep(value, ",")
+}
+
+func splitBySep(value, sep string) []string {
 	value = strings.TrimSpace(value)
-	parts := strings.Split(value, ",")
+	parts := strings.Split(value, sep)
 	out := parts[:0]
 	for _, x := range parts {
 		x = strings.TrimSpace(x)
@@ -994,6 +1532,28 @@ func hasBoolFlag(name string, args []string) bool {
 	return false
 }
 
+// findFlagValue finds the value of a non-boolean flag from args,
+// if the flag presents multiple times, the last value is returned.
+func findFlagValue(name string, args []string) (value string, found bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+		if !strings.HasPrefix(a, "-") || !strings.Contains(a, name) {
+			continue
+		}
+		a = strings.TrimLeft(a, "-")
+		if a == name && i+1 < len(args) {
+			value, found = args[i+1], true
+			i++
+		} else if strings.HasPrefix(a, name+"=") {
+			value, found = a[len(name)+1:], true
+		}
+	}
+	return
+}
+
 func spaceJoin(strList ...string) string {
 	result := ""
 	for _, s := range strList {
@@ -1007,6 +1567,29 @@ func spaceJoin(strList ...string) string {
 	return result
 }
 
+// splitPassthroughArgs splits args at the terminator "--".
+// A "--" which is value of a flag is not treated as the terminator.
+func splitPassthroughArgs(flagMap map[string]*_flag, args []string) (cmdArgs, rest []string, found bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			return clip(args[:i]), args[i+1:], true
+		}
+		if len(a) < 2 || a[0] != '-' {
+			continue
+		}
+		name := strings.TrimLeft(a, "-")
+		if strings.Contains(name, "=") {
+			continue
+		}
+		f := flagMap[name]
+		if f != nil && !f.isBoolFlag() {
+			i++
+		}
+	}
+	return args, nil, false
+}
+
 func findFlagIndex(cmdArgs []string) int {
 	flagIdx := len(cmdArgs)
 	for i, x := range cmdArgs {
@@ -0,0 +1,147 @@
+package mcli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// Kinds of FlagConstraint.
+const (
+	flagGroupExclusive        = "exclusive"
+	flagGroupOneRequired      = "one-required"
+	flagGroupRequiredTogether = "required-together"
+)
+
+// FlagConstraint declares a constraint on a group of flags,
+// see WithFlagConstraints.
+type FlagConstraint struct {
+	kind  string
+	names []string
+}
+
+// MutuallyExclusiveFlags declares that at most one of the flags can be set,
+// e.g. MutuallyExclusiveFlags("json", "yaml").
+func MutuallyExclusiveFlags(names ...string) FlagConstraint {
+	return FlagConstraint{kind: flagGroupExclusive, names: names}
+}
+
+// OneRequiredFlags declares that at least one of the flags must be set,
+// e.g. OneRequiredFlags("file", "url").
+func OneRequiredFlags(names ...string) FlagConstraint {
+	return FlagConstraint{kind: flagGroupOneRequired, names: names}
+}
+
+// RequiredTogetherFlags declares that if any one of the flags is set,
+// all of them must be set, e.g. RequiredTogetherFlags("cert", "key").
+func RequiredTogetherFlags(names ...string) FlagConstraint {
+	return FlagConstraint{kind: flagGroupRequiredTogether, names: names}
+}
+
+// flagGroup is a FlagConstraint with flag names resolved.
+type flagGroup struct {
+	kind  string
+	flags []*_flag
+}
+
+func (ctx *parsingContext) resolveFlagGroups() error {
+	for _, c := range ctx.opts.flagConstraints {
+		if len(c.names) < 2 {
+			return newProgramingError("flag constraint %s requires at least two flags, got %v", c.kind, c.names)
+		}
+		g := &flagGroup{kind: c.kind}
+		for _, name := range c.names {
+			f := ctx.flagMap[strings.TrimLeft(name, "-")]
+			if f == nil || f.nonflag {
+				return newProgramingError("flag constraint %s: flag %q is not defined", c.kind, name)
+			}
+			g.flags = append(g.flags, f)
+		}
+		for _, f := range g.flags {
+			f.groups = append(f.groups, g)
+		}
+		ctx.flagGroups = append(ctx.flagGroups, g)
+	}
+	return nil
+}
+
+func (ctx *parsingContext) checkFlagGroups() (err error) {
+	if len(ctx.flagGroups) == 0 {
+		return nil
+	}
+	isSet := make(map[string]bool)
+	ctx.getFlagSet().Visit(func(ff *flag.Flag) {
+		isSet[ff.Name] = true
+	})
+	for _, g := range ctx.flagGroups {
+		var setFlags, unsetFlags []string
+		for _, f := range g.flags {
+			if isSet[f.name] {
+				setFlags = append(setFlags, f.name)
+			} else {
+				unsetFlags = append(unsetFlags, f.name)
+			}
+		}
+		var invalid bool
+		switch g.kind {
+		case flagGroupExclusive:
+			invalid = len(setFlags) > 1
+		case flagGroupOneRequired:
+			invalid = len(setFlags) == 0
+		case flagGroupRequiredTogether:
+			invalid = len(setFlags) > 0 && len(unsetFlags) > 0
+		}
+		if invalid {
+			ctx.fail(&err, &FlagConstraintError{
+				Constraint: g.kind,
+				Flags:      g.names(),
+				SetFlags:   setFlags,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (g *flagGroup) names() []string {
+	out := make([]string, 0, len(g.flags))
+	for _, f := range g.flags {
+		out = append(out, f.name)
+	}
+	return out
+}
+
+func (g *flagGroup) formatForHelp(f *_flag) string {
+	var others []string
+	for _, x := range g.flags {
+		if x != f {
+			others = append(others, formatFlagName(x.name))
+		}
+	}
+	switch g.kind {
+	case flagGroupExclusive:
+		return fmt.Sprintf("[conflicts with: %s]", strings.Join(others, ", "))
+	case flagGroupOneRequired:
+		return fmt.Sprintf("[required: one of %s]", formatFlagNames(g.names()))
+	case flagGroupRequiredTogether:
+		return fmt.Sprintf("[requires: %s]", strings.Join(others, ", "))
+	}
+	return ""
+}
+
+func formatFlagName(name string) string {
+	if len(name) == 1 {
+		return "-" + name
+	}
+	return "--" + name
+}
+
+func formatFlagNames(names []string) string {
+	out := make([]string, 0, len(names))
+	for _, name := range names {
+		out = append(out, formatFlagName(name))
+	}
+	return strings.Join(out, ", ")
+}
@@ -0,0 +1,97 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse_FlagConstraints(t *testing.T) {
+	type cmdArgs struct {
+		JSON bool   `cli:"-j, --json"`
+		YAML bool   `cli:"-y, --yaml"`
+		File string `cli:"-f, --file"`
+		URL  string `cli:"--url" env:"TEST_MCLI_URL"`
+		Cert string `cli:"--cert"`
+		Key  string `cli:"--key"`
+	}
+	constraints := WithFlagConstraints(
+		MutuallyExclusiveFlags("json", "y"),
+		OneRequiredFlags("file", "url"),
+		RequiredTogetherFlags("cert", "key"),
+	)
+
+	cases := []struct {
+		args       []string
+		env        string
+		constraint string
+		want       string
+	}{
+		{args: []string{"-f", "a"}},
+		{args: []string{"-j"}, env: "http://x"},
+		{args: []string{"-f", "a", "--cert", "c", "--key", "k"}},
+		{
+			args:       []string{"-f", "a", "--json", "-y"},
+			constraint: "exclusive",
+			want:       "flags cannot be set together: --json, --yaml",
+		},
+		{
+			args:       []string{"-j"},
+			constraint: "one-required",
+			want:       "one of flags is required but not set: --file, --url",
+		},
+		{
+			args:       []string{"-f", "a", "--key", "k"},
+			constraint: "required-together",
+			want:       "flags must be set together: --cert, --key, missing: --cert",
+		},
+	}
+	for _, c := range cases {
+		var buf bytes.Buffer
+		resetDefaultApp()
+		if c.env != "" {
+			os.Setenv("TEST_MCLI_URL", c.env)
+		}
+		defaultApp.getFlagSet().SetOutput(&buf)
+		_, err := Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+			constraints, WithArgs(c.args))
+		if c.want == "" {
+			assert.Nil(t, err, "args: %v", c.args)
+			continue
+		}
+		var constraintErr *FlagConstraintError
+		if assert.True(t, errors.As(err, &constraintErr), "args: %v", c.args) {
+			assert.Equal(t, c.constraint, constraintErr.Constraint)
+			assert.Equal(t, c.want, err.Error())
+			assert.Contains(t, buf.String(), c.want+"\nUsage:")
+		}
+	}
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err := Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		constraints, WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	assert.Contains(t, got, "[conflicts with: --yaml]\n")
+	assert.Contains(t, got, "[conflicts with: --json]\n")
+	assert.Contains(t, got, "[required: one of --file, --url]\n")
+	assert.Contains(t, got, "[requires: --key]\n")
+	assert.Contains(t, got, "[requires: --cert]\n")
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		Parse(&cmdArgs{}, WithArgs([]string{}),
+			WithFlagConstraints(MutuallyExclusiveFlags("json", "xml")))
+	})
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		Parse(&cmdArgs{}, WithArgs([]string{}),
+			WithFlagConstraints(OneRequiredFlags("json")))
+	})
+}
@@ -0,0 +1,161 @@
+package mcli
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// flagValue wraps the value of a flag registered to the FlagSet,
+// the short name and long name of a flag share one flagValue.
+//
+// mcli parses flags from command line by itself (see parseOneFlag),
+// the FlagSet is populated when parsing, thus all names of a flag
+// are visible by (*flag.FlagSet).Visit after a flag is set.
+type flagValue struct {
+	flag.Value
+	f      *_flag
+	isBool bool
+
+	// renamedFrom is the old name of a renamed flag, which is accepted
+	// for compatibility, see tag `renamed`.
+	renamedFrom string
+
+	// names holds the names to mark as set in the FlagSet,
+	// when the flag is set by any one name.
+	names []string
+
+	// marking makes Set do nothing, it is used to mark a name as set
+	// in the FlagSet, without setting the value again, the Set operation
+	// may be not idempotent.
+	marking bool
+}
+
+func (v *flagValue) Get() any {
+	if g, ok := v.Value.(flag.Getter); ok {
+		return g.Get()
+	}
+	return v.Value.String()
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+func (v *flagValue) Set(s string) error {
+	if v.marking {
+		return nil
+	}
+	return v.Value.Set(s)
+}
+
+// setFlag sets the value of the named flag in the FlagSet,
+// other names of the flag are also marked as set.
+func setFlag(fs *flag.FlagSet, name, value string) error {
+	if err := fs.Set(name, value); err != nil {
+		return err
+	}
+	markFlag(fs, name)
+	return nil
+}
+
+// setFlagElems sets elements of a slice or map, which are already split,
+// names of a flag are marked as set in the FlagSet.
+func setFlagElems(fs *flag.FlagSet, f *_flag, elems []string) error {
+	for _, x := range elems {
+		if err := f.setElem(x); err != nil {
+			return err
+		}
+	}
+	if !f.nonflag && !f.isEnvVar {
+		markFlag(fs, f.name)
+	}
+	return nil
+}
+
+// markFlag marks names of the named flag as set in the FlagSet,
+// without changing the value.
+func markFlag(fs *flag.FlagSet, name string) {
+	ff := fs.Lookup(name)
+	if ff == nil {
+		return
+	}
+	v, ok := ff.Value.(*flagValue)
+	if !ok {
+		return
+	}
+	for _, x := range v.names {
+		if alias, ok := fs.Lookup(x).Value.(*flagValue); ok {
+			alias.marking = true
+			_ = fs.Set(x, "")
+			alias.marking = false
+		}
+	}
+}
+
+// parseOneFlag parses one flag from args, it has same syntax with
+// (*flag.FlagSet).Parse.
+// It returns the value of the flag which is set, and reports stop as true
+// when there is no more flags to parse, the remaining args are non-flag
+// arguments.
+func parseOneFlag(fs *flag.FlagSet, args []string) (rest []string, v *flagValue, stop bool, err error) {
+	s := args[0]
+	if len(s) < 2 || s[0] != '-' {
+		return args, nil, true, nil
+	}
+	numMinuses := 1
+	if s[1] == '-' {
+		numMinuses++
+		if len(s) == 2 { // "--" terminates the flags
+			return args[1:], nil, true, nil
+		}
+	}
+	name := s[numMinuses:]
+	if len(name) == 0 || name[0] == '-' || name[0] == '=' {
+		return args, nil, false, fmt.Errorf("bad flag syntax: %s", s)
+	}
+
+	args = args[1:]
+	value, hasValue := "", false
+	if i := strings.IndexByte(name, '='); i > 0 {
+		value, hasValue = name[i+1:], true
+		name = name[:i]
+	}
+	ff := fs.Lookup(name)
+	if ff == nil {
+		if name == "help" || name == "h" {
+			return args, nil, false, flag.ErrHelp
+		}
+		return args, nil, false, fmt.Errorf("flag provided but not defined: -%s", name)
+	}
+	if isBoolFlagValue(ff.Value) {
+		if !hasValue {
+			value = "true"
+		}
+	} else {
+		if !hasValue && len(args) > 0 {
+			value, hasValue, args = args[0], true, args[1:]
+		}
+		if !hasValue {
+			return args, nil, false, fmt.Errorf("flag needs an argument: -%s", name)
+		}
+	}
+	v, _ = ff.Value.(*flagValue)
+	if err = setFlag(fs, name, value); err != nil {
+		// Report the canonical long name, despite which name is used.
+		if v != nil {
+			name = v.f.name
+		}
+		return args, nil, false, &InvalidValueError{Name: name, Value: value, Err: err}
+	}
+	return args, v, false, nil
+}
+
+func isBoolFlagValue(v flag.Value) bool {
+	bv, ok := v.(interface{ IsBoolFlag() bool })
+	return ok && bv.IsBoolFlag()
+}
+
+// setFlagSetArgs sets the non-flag arguments returned by fs.Args(),
+// it also marks the FlagSet as parsed.
+func setFlagSetArgs(fs *flag.FlagSet, args []string) {
+	_ = fs.Parse(append([]string{"--"}, args...))
+}
@@ -209,3 +209,60 @@ func Test_findFlagIndex(t *testing.T) {
 		})
 	}
 }
+
+func TestParse_DeprecatedAndRenamed(t *testing.T) {
+	type cmdArgs struct {
+		Output  string `cli:"-o, --output" renamed:"out, --dest"`
+		Verbose bool   `cli:"#D, -v, --verbose"`
+		Format  string `cli:"--format" deprecated:"use --output-format instead"`
+		Level   string `cli:"--level" deprecated:"it has no effect"`
+		Name    string `cli:"name" deprecated:"use --name instead"`
+	}
+
+	resetDefaultApp()
+	var buf bytes.Buffer
+	defaultApp.getFlagSet().SetOutput(&buf)
+	args := &cmdArgs{}
+	fs, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--out", "a.txt", "-v", "--format", "json", "abc"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "a.txt", args.Output)
+	assert.True(t, args.Verbose)
+	assert.Equal(t, "json", args.Format)
+	assert.Equal(t, "abc", args.Name)
+	assert.Equal(t, "a.txt", fs.Lookup("output").Value.String())
+
+	got := buf.String()
+	assert.Equal(t, "Warning: flag '-out' is renamed, use flag '-output' instead\n"+
+		"Warning: flag '-format' is deprecated, use --output-format instead\n"+
+		"Warning: flag '-verbose' is deprecated\n"+
+		"Warning: argument 'name' is deprecated, use --name instead\n", got)
+
+	resetDefaultApp()
+	buf.Reset()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	args = &cmdArgs{}
+	_, err = Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"--dest=b.txt"}))
+	assert.Nil(t, err)
+	assert.Equal(t, "b.txt", args.Output)
+	assert.Equal(t, "Warning: flag '-dest' is renamed, use flag '-output' instead\n", buf.String())
+
+	// Old names are hidden from help.
+	buf.Reset()
+	defaultApp.printUsage()
+	got = buf.String()
+	assert.NotContains(t, got, "-out,")
+	assert.NotContains(t, got, "--dest")
+	assert.Contains(t, got, "      --format <string> [DEPRECATED]\n"+
+		"                                [deprecated: use --output-format instead]\n")
+
+	// Tag renamed is not allowed for an argument.
+	var args2 struct {
+		Name string `cli:"name" renamed:"old-name"`
+	}
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		Parse(&args2, WithErrorHandling(flag.ContinueOnError), WithArgs([]string{}))
+	})
+}
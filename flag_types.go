@@ -0,0 +1,251 @@
+package mcli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeTyp     = reflect.TypeOf(time.Time{})
+	urlTyp      = reflect.TypeOf(url.URL{})
+	byteSizeTyp = reflect.TypeOf(ByteSize(0))
+)
+
+// builtinTypeNames are the placeholders shown in help for values of
+// the builtin types, e.g. "--listen <ip:port>".
+var builtinTypeNames = map[reflect.Type]string{
+	reflect.TypeOf(time.Duration(0)): "duration",
+	timeTyp:                          "time",
+	urlTyp:                           "url",
+	byteSizeTyp:                      "size",
+	reflect.TypeOf(net.IP{}):         "ip",
+	reflect.TypeOf(netip.Addr{}):     "ip",
+	reflect.TypeOf(netip.Prefix{}):   "prefix",
+	reflect.TypeOf(netip.AddrPort{}): "ip:port",
+}
+
+// ByteSize is a size in bytes, it implements flag.Value to parse sizes
+// like "512MiB", "1.5GB", "64k".
+//
+// Units are case-insensitive, "KB", "MB", "GB", "TB", "PB" are powers
+// of 1000, "KiB", "MiB", "GiB", "TiB", "PiB" are powers of 1024,
+// single letter units "K", "M", "G", "T", "P" are also powers of 1024.
+// A value without unit, or with unit "B", is in bytes.
+type ByteSize int64
+
+var byteSizeUnits = []struct {
+	name string
+	size int64
+}{
+	{"PiB", 1 << 50}, {"PB", 1e15}, {"TiB", 1 << 40}, {"TB", 1e12}, {"GiB", 1 << 30},
+	{"GB", 1e9}, {"MiB", 1 << 20}, {"MB", 1e6}, {"KiB", 1 << 10}, {"KB", 1e3},
+	{"P", 1 << 50}, {"T", 1 << 40}, {"G", 1 << 30}, {"M", 1 << 20}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseByteSize parses a size string, e.g. "512MiB", "1.5GB", "64k".
+func ParseByteSize(s string) (ByteSize, error) {
+	str := strings.TrimSpace(s)
+	num, unit := str, ""
+	for i, c := range str {
+		if (c < '0' || c > '9') && c != '.' && c != '-' && c != '+' {
+			num, unit = strings.TrimSpace(str[:i]), strings.TrimSpace(str[i:])
+			break
+		}
+	}
+	mul := int64(1)
+	if unit != "" {
+		found := false
+		for _, u := range byteSizeUnits {
+			if strings.EqualFold(unit, u.name) {
+				mul, found = u.size, true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid size %q: unknown unit %q", s, unit)
+		}
+	}
+	if n, err := strconv.ParseInt(num, 10, 64); err == nil {
+		if n != 0 && (n*mul)/mul != n {
+			return 0, fmt.Errorf("invalid size %q: value out of range", s)
+		}
+		return ByteSize(n * mul), nil
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	f *= float64(mul)
+	if f >= 1<<63 || f < -(1<<63) {
+		return 0, fmt.Errorf("invalid size %q: value out of range", s)
+	}
+	return ByteSize(f), nil
+}
+
+// Set implements flag.Value.
+func (s *ByteSize) Set(value string) error {
+	x, err := ParseByteSize(value)
+	if err != nil {
+		return err
+	}
+	*s = x
+	return nil
+}
+
+// String formats the size with the largest unit which divides it exactly,
+// e.g. "512MiB", "1500KB", "100B".
+func (s ByteSize) String() string {
+	n := int64(s)
+	if n == 0 {
+		return "0B"
+	}
+	for _, u := range byteSizeUnits[:10] {
+		if n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// timeLayouts are the named layouts which can be used in tag `layout`.
+var timeLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"Kitchen":     time.Kitchen,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+}
+
+// parseLayoutTag parses the tag `layout` which specifies the layout to
+// parse time values, it can be either a layout name, e.g. "DateOnly",
+// or a layout string, e.g. "2006-01-02".
+func (f *_flag) parseLayoutTag(tag string) error {
+	if tag == "" {
+		return nil
+	}
+	if constraintElemType(f.rv.Type()) != timeTyp {
+		return newProgramingError("layout is only supported for time values, %s", f.helpName())
+	}
+	if layout, ok := timeLayouts[tag]; ok {
+		tag = layout
+	}
+	f.timeLayout = tag
+	return nil
+}
+
+// applyTimeValue parses s using layout and sets it to rv, which is
+// a time.Time, *time.Time, or a slice or map of time.Time.
+func applyTimeValue(rv reflect.Value, s, layout string) error {
+	if s == "" {
+		return nil
+	}
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return applyTimeValue(rv.Elem(), s, layout)
+	case reflect.Slice:
+		e := reflect.New(rv.Type().Elem()).Elem()
+		if err := applyTimeValue(e, s, layout); err != nil {
+			return err
+		}
+		rv.Set(reflect.Append(rv, e))
+		return nil
+	case reflect.Map:
+		if rv.IsNil() {
+			rv.Set(reflect.MakeMap(rv.Type()))
+		}
+		parts := append(strings.SplitN(s, "=", 2), "")
+		val := reflect.New(rv.Type().Elem()).Elem()
+		if err := applyTimeValue(val, parts[1], layout); err != nil {
+			return err
+		}
+		rv.SetMapIndex(reflect.ValueOf(parts[0]), val)
+		return nil
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return err
+	}
+	rv.Set(reflect.ValueOf(t))
+	return nil
+}
+
+// formatTimeValue formats a time.Time or *time.Time using layout.
+func formatTimeValue(rv reflect.Value, layout string) (string, bool) {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "", true
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() != timeTyp {
+		return "", false
+	}
+	t := rv.Interface().(time.Time)
+	if t.IsZero() {
+		return "", true
+	}
+	return t.Format(layout), true
+}
+
+// applyURLValue parses s as a URL and sets it to rv, which is
+// a url.URL or *url.URL.
+func applyURLValue(rv reflect.Value, s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" && u.Host == "" && u.Path == "" {
+		return errors.New("empty url")
+	}
+	if rv.Kind() == reflect.Pointer {
+		rv.Set(reflect.ValueOf(u))
+	} else {
+		rv.Set(reflect.ValueOf(*u))
+	}
+	return nil
+}
+
+func isURLType(typ reflect.Type) bool {
+	return typ == urlTyp || (typ.Kind() == reflect.Pointer && typ.Elem() == urlTyp)
+}
+
+// isScalarTextValue tells whether rv is a slice type which implements
+// encoding.TextUnmarshaler but not flag.Value, e.g. net.IP,
+// such a value is parsed as a whole, instead of being appended.
+func isScalarTextValue(rv reflect.Value) bool {
+	return rv.Kind() == reflect.Slice && isTextValueImpl(rv) && !isFlagValueImpl(rv)
+}
+
+// isSupportedElemType tells whether typ is supported as element type of
+// a slice or value type of a map.
+func isSupportedElemType(typ reflect.Type) bool {
+	if isSupportedBasicType(typ.Kind()) || isURLType(typ) {
+		return true
+	}
+	ptrTyp := reflect.PointerTo(typ)
+	return typ.Implements(flagValueTyp) || ptrTyp.Implements(flagValueTyp) ||
+		typ.Implements(textValueTyp) || ptrTyp.Implements(textValueTyp)
+}
+
+func derefType(typ reflect.Type) reflect.Type {
+	if typ.Kind() == reflect.Pointer {
+		return typ.Elem()
+	}
+	return typ
+}
@@ -0,0 +1,143 @@
+package mcli
+
+import (
+	"bytes"
+	"flag"
+	"net"
+	"net/netip"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteSize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want ByteSize
+		str  string
+	}{
+		{"0", 0, "0B"},
+		{"100", 100, "100B"},
+		{"100B", 100, "100B"},
+		{"64k", 64 << 10, "64KiB"},
+		{"512MiB", 512 << 20, "512MiB"},
+		{"512mib", 512 << 20, "512MiB"},
+		{"1.5GiB", 3 << 29, "1536MiB"},
+		{"1.5GB", 1500e6, "1500MB"},
+		{"2 TB", 2e12, "2TB"},
+		{"1PiB", 1 << 50, "1PiB"},
+	}
+	for _, c := range cases {
+		got, err := ParseByteSize(c.in)
+		assert.Nil(t, err, c.in)
+		assert.Equal(t, c.want, got, c.in)
+		assert.Equal(t, c.str, got.String(), c.in)
+	}
+
+	for _, in := range []string{"", "abc", "1XB", "1.2.3MB", "9999999PiB"} {
+		_, err := ParseByteSize(in)
+		assert.NotNil(t, err, in)
+	}
+}
+
+func TestParse_BuiltinTypes(t *testing.T) {
+	type cmdArgs struct {
+		Since    time.Time           `cli:"--since" layout:"DateOnly"`
+		Until    *time.Time          `cli:"--until" layout:"2006-01-02 15:04"`
+		Created  time.Time           `cli:"--created"`
+		Days     []time.Time         `cli:"--days" layout:"DateOnly" default:"2024-01-01,2024-02-01"`
+		Timeouts []time.Duration     `cli:"--timeouts"`
+		Endpoint *url.URL            `cli:"--endpoint"`
+		Mirrors  []url.URL           `cli:"--mirrors"`
+		MaxSize  ByteSize            `cli:"--max-size" default:"512MiB" max:"1GiB"`
+		Quotas   map[string]ByteSize `cli:"--quotas"`
+		IP       net.IP              `cli:"--ip"`
+		Addr     netip.Addr          `cli:"--addr"`
+		Listen   netip.AddrPort      `cli:"--listen"`
+		Allow    []netip.Prefix      `cli:"--allow"`
+		Host     net.IP              `cli:"host"`
+		Port     int                 `cli:"port"`
+	}
+
+	resetDefaultApp()
+	args := &cmdArgs{}
+	fs, err := Parse(args, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{
+			"127.0.0.1", "8080",
+			"--since", "2024-03-01",
+			"--until", "2024-03-02 10:30",
+			"--created", "2024-03-01T08:00:00Z",
+			"--timeouts", "1s", "--timeouts", "1m",
+			"--endpoint", "https://example.com/api",
+			"--mirrors", "https://a.example.com", "--mirrors", "https://b.example.com",
+			"--quotas", "alice=1GiB", "--quotas", "bob=10MB",
+			"--ip", "10.0.0.1",
+			"--addr", "::1",
+			"--listen", "0.0.0.0:80",
+			"--allow", "10.0.0.0/8", "--allow", "192.168.0.0/16",
+		}))
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-03-01", args.Since.Format("2006-01-02"))
+	assert.Equal(t, time.Date(2024, 3, 2, 10, 30, 0, 0, time.UTC), *args.Until)
+	assert.Equal(t, time.Date(2024, 3, 1, 8, 0, 0, 0, time.UTC), args.Created)
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}, args.Days)
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, args.Timeouts)
+	assert.Equal(t, "https://example.com/api", args.Endpoint.String())
+	assert.Equal(t, "b.example.com", args.Mirrors[1].Host)
+	assert.Equal(t, ByteSize(512<<20), args.MaxSize)
+	assert.Equal(t, map[string]ByteSize{"alice": 1 << 30, "bob": 10e6}, args.Quotas)
+	assert.Equal(t, "10.0.0.1", args.IP.String())
+	assert.Equal(t, netip.MustParseAddr("::1"), args.Addr)
+	assert.Equal(t, netip.MustParseAddrPort("0.0.0.0:80"), args.Listen)
+	assert.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.168.0.0/16"),
+	}, args.Allow)
+	assert.Equal(t, "127.0.0.1", args.Host.String())
+	assert.Equal(t, 8080, args.Port)
+	assert.Equal(t, "2024-03-01", fs.Lookup("since").Value.String())
+
+	for _, c := range [][]string{
+		{"--since", "2024/03/01"},
+		{"--max-size", "2GiB"},
+		{"--max-size", "1XB"},
+		{"--addr", "abc"},
+		{"--endpoint", "://bad"},
+	} {
+		var buf bytes.Buffer
+		resetDefaultApp()
+		defaultApp.getFlagSet().SetOutput(&buf)
+		_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+			WithArgs(append([]string{"127.0.0.1", "80"}, c...)))
+		assert.NotNil(t, err, "args: %v", c)
+	}
+
+	var buf bytes.Buffer
+	resetDefaultApp()
+	defaultApp.getFlagSet().SetOutput(&buf)
+	_, err = Parse(&cmdArgs{}, WithErrorHandling(flag.ContinueOnError),
+		WithArgs([]string{"-h"}))
+	assert.ErrorIs(t, err, flag.ErrHelp)
+	got := buf.String()
+	for _, want := range []string{
+		"-since <time>", "-days <[]time>", "-timeouts <[]duration>",
+		"-endpoint <url>", "-mirrors <[]url>", "-max-size <size>",
+		"-quotas <map[string]size>", "-ip <ip>", "-addr <ip>",
+		"-listen <ip:port>", "-allow <[]prefix>", "host <ip>",
+	} {
+		assert.Contains(t, got, want)
+	}
+
+	resetDefaultApp()
+	assert.Panics(t, func() {
+		var args struct {
+			Name string `cli:"--name" layout:"DateOnly"`
+		}
+		Parse(&args, WithArgs([]string{}))
+	})
+}
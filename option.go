@@ -24,6 +24,8 @@ type parseOptions struct {
 	defaults     map[string]any
 	enums        map[string][]string
 
+	flagConstraints []FlagConstraint
+
 	customUsage func() string
 	helpFooter  func() string
 
@@ -102,6 +104,27 @@ func WithEnums(enums map[string][]string) ParseOpt {
 	}}
 }
 
+// WithFlagConstraints declares constraints on groups of flags,
+// e.g. flags which cannot be set together, flags which one of them
+// is required, and flags which must be set together.
+// A flag is considered as set when its value is given from command line,
+// environment variables or config file.
+// The flag names can be either the short names or the long names,
+// violations are reported as FlagConstraintError, and the constraints
+// are shown in help.
+//
+// e.g.
+//
+//	WithFlagConstraints(
+//		MutuallyExclusiveFlags("json", "yaml"),
+//		RequiredTogetherFlags("cert", "key"),
+//	)
+func WithFlagConstraints(constraints ...FlagConstraint) ParseOpt {
+	return ParseOpt{f: func(options *parseOptions) {
+		options.flagConstraints = append(options.flagConstraints, constraints...)
+	}}
+}
+
 // WithExamples specifies examples for a command.
 // Examples will be shown after flags in the command's help.
 func WithExamples(examples string) ParseOpt {
@@ -129,6 +152,10 @@ type cmdOptions struct {
 	longDesc             string
 	enableFlagCompletion bool
 	argCompFunc          ArgCompletionFunc
+
+	deprecated    bool
+	deprecatedMsg string
+	experimental  bool
 }
 
 func (p *cmdOptions) apply(opts ...CmdOpt) *cmdOptions {
@@ -167,3 +194,26 @@ func EnableFlagCompletion() CmdOpt {
 		options.enableFlagCompletion = true
 	}}
 }
+
+// Deprecated marks a command as deprecated, "(DEPRECATED)" will be shown
+// in help, and a warning with the message is printed when the command
+// is invoked, e.g. `Deprecated(\`use "issue create" instead\`)`.
+func Deprecated(message string) CmdOpt {
+	return CmdOpt{f: func(options *cmdOptions) {
+		options.deprecated = true
+		options.deprecatedMsg = strings.TrimSpace(message)
+	}}
+}
+
+// Experimental marks a command as experimental.
+// An experimental command is hidden and cannot be invoked, unless
+// it is enabled by Options.EnableExperimental, the environment variable
+// specified by Options.ExperimentalEnv, or the special flag
+// "--mcli-experimental".
+// When an enabled experimental command is invoked, a warning is printed
+// to tell that the command may change or be removed in future releases.
+func Experimental() CmdOpt {
+	return CmdOpt{f: func(options *cmdOptions) {
+		options.experimental = true
+	}}
+}
@@ -0,0 +1,153 @@
+package mcli
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// plugin is an external command, which is an executable named
+// "<program>-<name>" found in the plugin directories.
+type plugin struct {
+	name string
+	path string
+}
+
+// getPluginDirs returns Options.PluginDirs if it is set, else the
+// directories in PATH. Like exec.LookPath, empty and relative directories
+// in PATH are ignored, thus a plugin is not found in the current directory
+// unexpectedly.
+func (p *App) getPluginDirs() []string {
+	if len(p.PluginDirs) > 0 {
+		return p.PluginDirs
+	}
+	var dirs []string
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir != "" && filepath.IsAbs(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+func getPluginPrefix() string {
+	name := getProgramName()
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+	return name + "-"
+}
+
+// findPlugins returns the plugins found in the plugin directories,
+// sorted by name. A plugin which has same name with a command is ignored,
+// and if there are plugins with same name, the first one is taken.
+func (p *App) findPlugins() []plugin {
+	prefix := getPluginPrefix()
+	seen := make(map[string]bool)
+	var out []plugin
+	for _, dir := range p.getPluginDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			fileName := entry.Name()
+			if !strings.HasPrefix(fileName, prefix) {
+				continue
+			}
+			name := strings.TrimPrefix(fileName, prefix)
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			if !isValidPluginName(name) || seen[name] || p.cmds.isValid(name) {
+				continue
+			}
+			path := filepath.Join(dir, fileName)
+			if !isExecutableFile(path) {
+				continue
+			}
+			seen[name] = true
+			out = append(out, plugin{name: name, path: path})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].name < out[j].name
+	})
+	return out
+}
+
+// lookupPlugin searches the plugin directories for the plugin name,
+// it returns the path of the executable.
+func (p *App) lookupPlugin(name string) (path string, found bool) {
+	if !isValidPluginName(name) {
+		return "", false
+	}
+	fileName := getPluginPrefix() + name
+	for _, dir := range p.getPluginDirs() {
+		path = filepath.Join(dir, fileName)
+		if runtime.GOOS == "windows" {
+			path += ".exe"
+		}
+		if isExecutableFile(path) {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+// runPlugin runs the plugin with args, the plugin inherits the
+// environment variables and standard input and outputs.
+func (p *App) runPlugin(name, path string, args []string) error {
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return &PluginError{Plugin: name, Path: path, Err: err}
+	}
+	return nil
+}
+
+func (p *App) suggestedPlugins(cmdWord string) []string {
+	var result []string
+	for _, x := range p.findPlugins() {
+		if strings.HasPrefix(x.name, cmdWord) {
+			result = append(result, p.formatCompletion(x.name, x.path))
+		}
+	}
+	return result
+}
+
+// findPluginWord returns index of the first word in args which is not
+// a flag, it returns -1 if there is no such word before "--".
+func findPluginWord(args []string) int {
+	for i, x := range args {
+		if x == "--" {
+			break
+		}
+		if !strings.HasPrefix(x, "-") {
+			return i
+		}
+	}
+	return -1
+}
+
+// isValidPluginName tells whether name can be invoked as a command word,
+// a name starting with "-" would be taken as a flag.
+func isValidPluginName(name string) bool {
+	return name != "" && !strings.HasPrefix(name, "-") && !strings.ContainsAny(name, `/\`)
+}
+
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		return strings.EqualFold(filepath.Ext(path), ".exe")
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
@@ -0,0 +1,114 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApp_Plugins(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin scripts are not executable on windows")
+	}
+	dir := t.TempDir()
+	prefix := getPluginPrefix()
+	writePlugin := func(name, content string, perm os.FileMode) string {
+		path := filepath.Join(dir, prefix+name)
+		err := os.WriteFile(path, []byte("#!/bin/sh\n"+content+"\n"), perm)
+		require.Nil(t, err)
+		return path
+	}
+	outFile := filepath.Join(dir, "out.txt")
+	helloPath := writePlugin("hello", `echo "$TEST_MCLI_PLUGIN" "$@" > "`+outFile+`"`, 0o755)
+	writePlugin("fail", "exit 3", 0o755)
+	writePlugin("cmd1", "exit 0", 0o755)
+	writePlugin("noexec", "exit 0", 0o644)
+
+	var buf bytes.Buffer
+	newTestApp := func() *App {
+		app := NewApp()
+		app.EnablePlugins = true
+		app.PluginDirs = []string{dir}
+		app.Add("cmd1", dummyCmd, "A cmd1 description")
+		app.getFlagSet().SetOutput(&buf)
+		return app
+	}
+
+	os.Setenv("TEST_MCLI_PLUGIN", "env")
+	defer os.Unsetenv("TEST_MCLI_PLUGIN")
+	err := newTestApp().RunE("hello", "a", "-b", "--c=1")
+	assert.Nil(t, err)
+	out, _ := os.ReadFile(outFile)
+	assert.Equal(t, "env a -b --c=1\n", string(out))
+
+	err = newTestApp().RunE("fail")
+	var pluginErr *PluginError
+	require.True(t, errors.As(err, &pluginErr))
+	assert.Equal(t, "fail", pluginErr.Plugin)
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+
+	// The plugin name is the first word which is not a flag,
+	// flags before it are passed to the plugin.
+	writePlugin("-v", "exit 4", 0o755)
+	err = newTestApp().RunE("-v", "hello", "a")
+	assert.Nil(t, err)
+	out, _ = os.ReadFile(outFile)
+	assert.Equal(t, "env -v a\n", string(out))
+
+	// The root command receives the arguments, plugins are not available.
+	rootCalled := false
+	app := newTestApp()
+	app.AddRoot(func() { rootCalled = true })
+	err = app.RunE("hello")
+	assert.Nil(t, err)
+	assert.True(t, rootCalled)
+
+	buf.Reset()
+	err = newTestApp().RunE("noexec")
+	var cmdErr *InvalidCommandError
+	assert.True(t, errors.As(err, &cmdErr))
+
+	// Empty and relative directories in PATH are ignored.
+	wd, _ := os.Getwd()
+	require.Nil(t, os.Chdir(dir))
+	defer os.Chdir(wd)
+	oldPath := os.Getenv("PATH")
+	defer os.Setenv("PATH", oldPath)
+	os.Setenv("PATH", string(filepath.ListSeparator)+".")
+	app = newTestApp()
+	app.PluginDirs = nil
+	assert.Nil(t, app.getPluginDirs())
+	buf.Reset()
+	err = app.RunE("hello")
+	assert.True(t, errors.As(err, &cmdErr))
+	os.Chdir(wd)
+
+	app = newTestApp()
+	assert.Equal(t, []plugin{
+		{name: "fail", path: filepath.Join(dir, prefix+"fail")},
+		{name: "hello", path: helloPath},
+	}, app.findPlugins())
+
+	buf.Reset()
+	err = app.RunE()
+	assert.Nil(t, err)
+	assert.Contains(t, buf.String(), "Plugins:\n  fail     "+filepath.Join(dir, prefix+"fail")+"\n"+
+		"  hello    "+helloPath+"\n")
+
+	var compBuf bytes.Buffer
+	app = newTestApp()
+	app.completionCtx.out = &compBuf
+	app.completionCtx.postFunc = func() {}
+	err = app.RunE("h", completionFlag, "powershell")
+	assert.Nil(t, err)
+	assert.Equal(t, "hello\n", compBuf.String())
+}
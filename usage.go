@@ -34,6 +34,15 @@ type usagePrinter struct {
 	cmdFlagHelp    []usageItem
 	nonFlagHelp    []usageItem
 	envVarsHelp    []usageItem
+
+	// groupedFlagHelp are flags of prefixed nested structs,
+	// which are grouped under headings.
+	groupedFlagHelp []flagHelpGroup
+}
+
+type flagHelpGroup struct {
+	heading string
+	items   []usageItem
 }
 
 func (p *usagePrinter) Do() {
@@ -46,9 +55,11 @@ func (p *usagePrinter) Do() {
 	}
 
 	globalFlags := p.app.getGlobalFlags()
-	if !ctx.parsed && globalFlags != nil {
+	configFlags := p.app.getConfigFlags()
+	if !ctx.parsed && (globalFlags != nil || configFlags != nil) {
 		wrapArgs := &withGlobalFlagArgs{
 			GlobalFlags: globalFlags,
+			ConfigFlags: configFlags,
 		}
 		err := ctx.parseTags(reflect.ValueOf(wrapArgs).Elem())
 		if err != nil {
@@ -124,7 +135,7 @@ func (p *usagePrinter) printUsageLine() {
 func (p *usagePrinter) commandLineFlagAndSubCmdInfo(cmdName string) string {
 	ctx := p.ctx
 	hasFlags := len(ctx.flags) > 0
-	hasNonflags := len(ctx.nonflags) > 0
+	hasNonflags := len(ctx.nonflags) > 0 || ctx.passthrough != nil
 	hasSubCmds := len(p.subCmds) > 0
 
 	usage := ""
@@ -148,6 +159,13 @@ func (p *usagePrinter) commandLineFlagAndSubCmdInfo(cmdName string) string {
 				usage += fmt.Sprintf(" [%s]", name)
 			}
 		}
+		if f := ctx.passthrough; f != nil {
+			if f.required {
+				usage += fmt.Sprintf(" -- <%s...>", f.name)
+			} else {
+				usage += fmt.Sprintf(" [-- %s...]", f.name)
+			}
+		}
 	}
 	if !hasFlags && !hasNonflags && hasSubCmds {
 		usage += " <command> ..."
@@ -165,6 +183,26 @@ func (p *usagePrinter) printSubCommands() {
 		keepCmdOrder := p.app.Options.KeepCommandOrder
 		p.__printSubCommands(out, subCmds, parentCmdName, showHidden, keepCmdOrder)
 	}
+	if ctx.name == "" && p.app.EnablePlugins {
+		p.printPlugins()
+	}
+}
+
+func (p *usagePrinter) printPlugins() {
+	plugins := p.app.findPlugins()
+	if len(plugins) == 0 {
+		return
+	}
+	var lines []usageItem
+	for _, x := range plugins {
+		lines = append(lines, usageItem{
+			prefix:      "  " + x.name,
+			description: x.path,
+		})
+	}
+	fmt.Fprint(p.out, "Plugins:\n")
+	printWithAlignment(p.out, lines, 0)
+	fmt.Fprint(p.out, "\n")
 }
 
 func (p *usagePrinter) countFlags() {
@@ -188,6 +226,8 @@ func (p *usagePrinter) splitAndFormatFlags() {
 		cmdFlagHelp    []usageItem
 		nonFlagHelp    []usageItem
 		envVarsHelp    []usageItem
+
+		groupedFlagHelp = make([]flagHelpGroup, len(p.ctx.headings))
 	)
 	if p.flagCount > 0 {
 		for _, f := range flags {
@@ -197,6 +237,8 @@ func (p *usagePrinter) splitAndFormatFlags() {
 			usage := f.getUsage(hasShortFlag)
 			if f.isGlobal {
 				globalFlagHelp = append(globalFlagHelp, usage)
+			} else if idx := find(p.ctx.headings, f.heading); idx >= 0 {
+				groupedFlagHelp[idx].items = append(groupedFlagHelp[idx].items, usage)
 			} else {
 				cmdFlagHelp = append(cmdFlagHelp, usage)
 			}
@@ -206,21 +248,42 @@ func (p *usagePrinter) splitAndFormatFlags() {
 		usage := f.getUsage(false)
 		nonFlagHelp = append(nonFlagHelp, usage)
 	}
+	if f := p.ctx.passthrough; f != nil {
+		usage := f.getUsage(false)
+		nonFlagHelp = append(nonFlagHelp, usage)
+	}
 	for _, f := range p.ctx.envVars {
 		usage := f.getUsage(false)
 		envVarsHelp = append(envVarsHelp, usage)
 	}
 	p.globalFlagHelp = globalFlagHelp
 	p.cmdFlagHelp = cmdFlagHelp
+	for i, heading := range p.ctx.headings {
+		groupedFlagHelp[i].heading = heading
+	}
+	p.groupedFlagHelp = groupedFlagHelp
 	p.nonFlagHelp = nonFlagHelp
 	p.envVarsHelp = envVarsHelp
 }
 
 func (p *usagePrinter) printCmdFlags() {
 	out := p.out
+	lineGroups := [][]usageItem{p.cmdFlagHelp}
+	for _, g := range p.groupedFlagHelp {
+		lineGroups = append(lineGroups, g.items)
+	}
+	maxPrefixLen := calcMaxPrefixLen(lineGroups)
 	if len(p.cmdFlagHelp) > 0 {
 		fmt.Fprint(out, "Flags:\n")
-		printWithAlignment(out, p.cmdFlagHelp, 0)
+		printWithAlignment(out, p.cmdFlagHelp, maxPrefixLen)
+		fmt.Fprint(out, "\n")
+	}
+	for _, g := range p.groupedFlagHelp {
+		if len(g.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(out, "%s:\n", g.heading)
+		printWithAlignment(out, g.items, maxPrefixLen)
 		fmt.Fprint(out, "\n")
 	}
 }
@@ -306,7 +369,7 @@ func (p *usagePrinter) __printSubCommands(out io.Writer, cmds commands, parentCm
 	preName := ""
 	for _, cmd := range cmds {
 		cmdName := trimPrefix(cmd.Name, parentCmdName)
-		if cmdName == "" || (cmd.Hidden && !showHidden) {
+		if cmdName == "" || (cmd.isHidden() && !showHidden) {
 			continue
 		}
 		if preName != "" && cmdName != preName {
@@ -326,9 +389,7 @@ func (p *usagePrinter) __printSubCommands(out io.Writer, cmds commands, parentCm
 		}
 		name := strings.Repeat("  ", len(prefix)) + leafCmdName
 		description := cmd.Description
-		if cmd.Hidden {
-			name += " (HIDDEN)"
-		}
+		name += cmd.lifecycleTag()
 		cmdLines = append(cmdLines, usageItem{
 			prefix:      name,
 			description: description,
@@ -361,14 +422,12 @@ func (p *usagePrinter) __printGroupedSubCommands(out io.Writer, cmdGroups []*cat
 		var grpLines []usageItem
 		for _, cmd := range grp.commands {
 			cmdName := cmd.Name
-			if cmdName == "" || (cmd.Hidden && !showHidden) || cmd.level > 1 {
+			if cmdName == "" || (cmd.isHidden() && !showHidden) || cmd.level > 1 {
 				continue
 			}
 			name := "  " + cmdName
 			description := cmd.Description
-			if cmd.Hidden {
-				name += " (HIDDEN)"
-			}
+			name += cmd.lifecycleTag()
 			grpLines = append(grpLines, usageItem{
 				prefix:      name,
 				description: description,
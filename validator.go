@@ -0,0 +1,54 @@
+package mcli
+
+import "reflect"
+
+// Validator is an optional interface which can be implemented by
+// argument structs, nested structs and global flags.
+// After flags and arguments are parsed and checked, the Validate method
+// is called, a non-nil error is reported as a parsing error.
+type Validator interface {
+	Validate() error
+}
+
+// ContextValidator is similar to Validator, but it receives the Context
+// of the running command.
+type ContextValidator interface {
+	Validate(ctx *Context) error
+}
+
+// getValidator returns the struct value rv as a Validator or
+// ContextValidator, else it returns nil.
+func getValidator(rv reflect.Value) any {
+	var x any
+	if rv.CanAddr() {
+		x = rv.Addr().Interface()
+	} else if rv.CanInterface() {
+		x = rv.Interface()
+	}
+	switch x.(type) {
+	case Validator, ContextValidator:
+		return x
+	}
+	return nil
+}
+
+// runValidators calls the Validate methods of argument structs,
+// nested structs are validated before the struct which contains them.
+func (ctx *parsingContext) runValidators() (err error) {
+	for _, v := range ctx.validators {
+		var e error
+		switch v := v.(type) {
+		case Validator:
+			e = v.Validate()
+		case ContextValidator:
+			e = v.Validate(newContext(ctx.app))
+		}
+		if e != nil {
+			ctx.fail(&err, e)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package mcli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validateTLSArgs struct {
+	Cert string `cli:"--cert"`
+	Key  string `cli:"--key"`
+}
+
+func (a *validateTLSArgs) Validate() error {
+	if (a.Cert == "") != (a.Key == "") {
+		return errors.New("--cert and --key must be given together")
+	}
+	return nil
+}
+
+type validateCmdArgs struct {
+	TLS   validateTLSArgs
+	Start int `cli:"--start"`
+	End   int `cli:"--end"`
+}
+
+func (a *validateCmdArgs) Validate(ctx *Context) error {
+	if a.Start > a.End {
+		return errors.New("--start must not be greater than --end in " + ctx.Command.Name)
+	}
+	return nil
+}
+
+type validateGlobalFlags struct {
+	Verbose bool   `cli:"-v, --verbose"`
+	Output  string `cli:"-o, --output"`
+}
+
+func (g *validateGlobalFlags) Validate() error {
+	if g.Verbose && g.Output == "quiet" {
+		return errors.New("--verbose conflicts with --output=quiet")
+	}
+	return nil
+}
+
+func TestParse_Validate(t *testing.T) {
+	var called bool
+	newTestApp := func(buf *bytes.Buffer) *App {
+		called = false
+		app := NewApp()
+		app.SetGlobalFlags(&validateGlobalFlags{})
+		app.Add("cmd1", NewCommandE(func(ctx *Context, args *validateCmdArgs) error {
+			called = true
+			return nil
+		}), "A cmd1 description")
+		app.getFlagSet().SetOutput(buf)
+		return app
+	}
+
+	var buf bytes.Buffer
+	err := newTestApp(&buf).RunE("cmd1", "--start", "1", "--end", "2", "--cert", "c", "--key", "k")
+	assert.Nil(t, err)
+	assert.True(t, called)
+
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"cmd1", "--start", "3", "--end", "2"}, "--start must not be greater than --end in cmd1"},
+		{[]string{"cmd1", "--cert", "c"}, "--cert and --key must be given together"},
+		{[]string{"cmd1", "-v", "-o", "quiet"}, "--verbose conflicts with --output=quiet"},
+	}
+	for _, c := range cases {
+		buf.Reset()
+		err = newTestApp(&buf).RunE(c.args...)
+		assert.False(t, called)
+		if assert.NotNil(t, err, "args: %v", c.args) {
+			assert.Equal(t, c.want, err.Error())
+		}
+		assert.Contains(t, buf.String(), c.want+"\n")
+		assert.Contains(t, buf.String(), "Usage:\n")
+	}
+
+	// Nested structs are validated first.
+	buf.Reset()
+	err = newTestApp(&buf).RunE("cmd1", "--start", "3", "--end", "2", "--cert", "c")
+	assert.Equal(t, "--cert and --key must be given together", err.Error())
+
+	// All errors are reported together.
+	buf.Reset()
+	app := newTestApp(&buf)
+	app.Options.ReportAllErrors = true
+	err = app.RunE("cmd1", "--start", "3", "--end", "2", "--cert", "c")
+	var validationErrs ValidationErrors
+	if assert.True(t, errors.As(err, &validationErrs)) {
+		assert.Len(t, validationErrs, 2)
+	}
+}